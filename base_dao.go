@@ -20,38 +20,101 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
+var ctx_key_sql_capture = P("")
+
+// SqlCapture records the first statement executed with the context returned by CaptureSql, the statements are not
+// executed. It is used to implement ToSql of the generated daos.
+type SqlCapture struct {
+	sql      string
+	args     []any
+	captured bool
+}
+
+func (c *SqlCapture) capture(sql string, args []any) {
+	if !c.captured {
+		c.sql, c.args, c.captured = sql, args, true
+	}
+}
+
+// Result returns the captured statement, err is the error of building the statement.
+func (c *SqlCapture) Result(err error) (sql string, args []any, _ error) {
+	if err != nil {
+		return "", nil, err
+	}
+	return c.sql, c.args, nil
+}
+
+func CaptureSql(ctx context.Context) (context.Context, *SqlCapture) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c := &SqlCapture{}
+	return context.WithValue(ctx, ctx_key_sql_capture, c), c
+}
+
+// SqlCaptureOf returns the SqlCapture of ctx, nil if ctx is not returned by CaptureSql.
+func SqlCaptureOf(ctx context.Context) *SqlCapture {
+	return getSqlCapture(ctx)
+}
+
+func getSqlCapture(ctx context.Context) *SqlCapture {
+	if ctx != nil {
+		c, _ := ctx.Value(ctx_key_sql_capture).(*SqlCapture)
+		return c
+	}
+	return nil
+}
+
 type baseDao struct {
-	db *sql.DB
+	db       *sql.DB
+	resolver DBResolver
+	timeout  time.Duration
+	table    string
+	// overrides the global config, nil means the global config is used.
+	cfg *CfgOverride
+}
+
+// config returns the global config overridden by the config of the dao.
+func (d baseDao) config() *Cfg {
+	return globalCfg().override(d.cfg)
 }
 
 func (d baseDao) DB() *sql.DB {
+	if d.resolver != nil {
+		return d.resolver.Primary()
+	}
 	if d.db == nil { // coverage-ignore
-		return global.DefaultDB
+		return d.config().DefaultDB
 	}
 	return d.db
 }
 
-func (d baseDao) query(ctx context.Context, sql string, args []any) (rows *sql.Rows, columns []string, closeFunc func(), err error) {
-	if ctx == nil {
-		ctx = context.Background()
-	}
-	prepare, err := d.createPrepare(ctx, sql)
+func (d baseDao) query(ctx context.Context, sql string, args []any, write bool, timeout time.Duration) (rows *sql.Rows, columns []string, closeFunc func(), err error) {
+	cfg := d.config()
+	ctx, cancel := d.withTimeout(ctx, timeout)
+	prepare, err := d.createPrepare(ctx, sql, !write)
 	if err != nil { // coverage-ignore
-		return nil, nil, nil, err
+		cancel()
+		return nil, nil, nil, translateError(ctx, err)
 	}
-	args = convertArgs(args)
+	args = d.bindArgs(ctx, args)
 	rows, err = prepare.QueryContext(ctx, args...)
-	if err != nil { // coverage-ignore
-		printWarn(ctx, prepare.Close())
-		return nil, nil, nil, err
+	if err != nil {
+		cfg.printWarn(ctx, prepare.Close())
+		cancel()
+		return nil, nil, nil, translateError(ctx, err)
 	}
 	closeFunc = func() {
-		printWarn(ctx, rows.Close())
-		printWarn(ctx, prepare.Close())
+		cfg.printWarn(ctx, rows.Close())
+		cfg.printWarn(ctx, prepare.Close())
+		cancel()
 	}
 	columns, err = rows.Columns()
 	if err != nil { // coverage-ignore
@@ -61,32 +124,75 @@ func (d baseDao) query(ctx context.Context, sql string, args []any) (rows *sql.R
 	return rows, columns, closeFunc, nil
 }
 
-func (d baseDao) exec(ctx context.Context, sql string, args []any) (result sql.Result, affected int64, err error) {
-	if ctx == nil {
-		ctx = context.Background()
-	}
+func (d baseDao) exec(ctx context.Context, sql string, args []any, timeout time.Duration) (result sql.Result, affected int64, err error) {
+	ctx, cancel := d.withTimeout(ctx, timeout)
+	defer cancel()
 	affected = int64(-1)
-	prepare, err := d.createPrepare(ctx, sql)
+	prepare, err := d.createPrepare(ctx, sql, false)
 	if err != nil { // coverage-ignore
-		return nil, 0, err
+		return nil, 0, translateError(ctx, err)
 	}
 	defer func() {
-		printWarn(ctx, prepare.Close())
+		d.config().printWarn(ctx, prepare.Close())
 	}()
-	args = convertArgs(args)
+	args = d.bindArgs(ctx, args)
 	result, err = prepare.ExecContext(ctx, args...)
-	if err != nil { // coverage-ignore
-		return nil, 0, err
+	if err != nil {
+		return nil, 0, translateError(ctx, err)
 	}
 	affected, err = result.RowsAffected()
 	return
 }
 
-func (d baseDao) createPrepare(ctx context.Context, _sql string) (*sql.Stmt, error) {
-	if tx := getTx(ctx); tx != nil {
-		return tx.PrepareContext(ctx, _sql)
+// bindArgs converts the args to be sent to the driver, the slices are converted to the arrays in PostgreSQL.
+func (d baseDao) bindArgs(ctx context.Context, args []any) []any {
+	args = convertArgs(args)
+	if DialectOf(d.dbOf(ctx)).Is(Dialect_.POSTGRES) {
+		for i, a := range args {
+			if t := reflect.TypeOf(a); t != nil && isPgArrayType(t) {
+				args[i] = PgArray(a)
+			}
+		}
+	}
+	return args
+}
+
+// withTimeout applies the timeout of the statement, the timeout of the dao and the configured timeout in order, the
+// first positive one is used.
+func (d baseDao) withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for _, t := range []time.Duration{timeout, d.timeout, d.config().Timeout} {
+		if t > 0 {
+			return context.WithTimeoutCause(ctx, t, ErrTimeout)
+		}
+	}
+	return ctx, func() {}
+}
+
+// translateError marks the error caused by the statement timeout with ErrTimeout, other errors are translated by
+// TranslateError.
+func translateError(ctx context.Context, err error) error {
+	if errors.Is(context.Cause(ctx), ErrTimeout) {
+		return &translatedError{sentinel: ErrTimeout, err: err}
+	}
+	return TranslateError(err)
+}
+
+// createPrepare prepares the statement in the transaction of ctx if exists, otherwise, the read statement is prepared
+// on a replica if the dao has a DBResolver. The shard of ctx takes precedence over the *sql.DB of the dao.
+func (d baseDao) createPrepare(ctx context.Context, _sql string, read bool) (*sql.Stmt, error) {
+	db := d.dbOf(ctx)
+	if tc := lookupTxCtx(ctx, db); tc != nil {
+		tc.countStmt()
+		return tc.tx.PrepareContext(ctx, _sql)
 	} else {
-		db := d.DB()
+		if _, sharded := ShardOf(ctx); read && !sharded && d.resolver != nil && !isUsePrimary(ctx) {
+			if replica := d.resolver.Replica(ctx); replica != nil {
+				db = replica
+			}
+		}
 		if db == nil { // coverage-ignore
 			return nil, errors.New("no available *sql.DB variable")
 		}
@@ -94,8 +200,35 @@ func (d baseDao) createPrepare(ctx context.Context, _sql string) (*sql.Stmt, err
 	}
 }
 
-func newBaseDao(db *sql.DB) *baseDao {
-	return &baseDao{db: db}
+// dbOf returns the *sql.DB of the shard of ctx if exists, otherwise, returns the *sql.DB of the dao.
+func (d baseDao) dbOf(ctx context.Context) *sql.DB {
+	if shard, ok := ShardOf(ctx); ok && shard.DB != nil {
+		return shard.DB
+	}
+	return d.DB()
+}
+
+func (d baseDao) newError(ctx context.Context, op Op, desc, sql string, args []any, start time.Time, err error) *Error {
+	if d.config().RedactErrorArgs {
+		redacted := make([]any, len(args))
+		for i := range redacted {
+			redacted[i] = "***"
+		}
+		args = redacted
+	}
+	return &Error{
+		Op:      op,
+		Desc:    desc,
+		Sql:     sql,
+		Args:    args,
+		Elapsed: time.Since(start),
+		InTx:    lookupTxCtx(ctx, d.dbOf(ctx)) != nil,
+		Err:     err,
+	}
+}
+
+func newBaseDao(db *sql.DB, resolver DBResolver, timeout time.Duration, table string, cfg *CfgOverride) *baseDao {
+	return &baseDao{db: db, resolver: resolver, timeout: timeout, table: table, cfg: cfg}
 }
 
 type Separate struct {
@@ -109,6 +242,10 @@ type BaseSqlBuilder struct {
 	argNum int
 	ok     bool
 	err    error
+	// the columns of the sensitive args by index.
+	sensitiveArgs map[int]string
+	// the columns of the sensitive field values got by EachColumn and ColumnValue of DaoSqlBuilder.
+	sensitiveValues map[sensitiveKey]string
 }
 
 func (b *BaseSqlBuilder) Write(str string, args ...any) *BaseSqlBuilder {
@@ -117,8 +254,23 @@ func (b *BaseSqlBuilder) Write(str string, args ...any) *BaseSqlBuilder {
 	return b
 }
 
+// SetArgs appends the args, the args bound to the sensitive columns are recorded to be redacted in the logs.
 func (b *BaseSqlBuilder) SetArgs(args ...any) {
-	b.args = append(b.args, args...)
+	for _, arg := range args {
+		column, sensitive := "", false
+		if s, ok := arg.(sensitiveArg); ok {
+			column, sensitive, arg = s.column, true, s.value
+		} else if key, ok := sensitiveKeyOf(reflect.ValueOf(arg)); ok {
+			column, sensitive = b.sensitiveValues[key]
+		}
+		if sensitive {
+			if b.sensitiveArgs == nil {
+				b.sensitiveArgs = make(map[int]string)
+			}
+			b.sensitiveArgs[len(b.args)] = column
+		}
+		b.args = append(b.args, arg)
+	}
 }
 
 func (b *BaseSqlBuilder) Pp(prefix string) string {
@@ -134,6 +286,22 @@ func (b *BaseSqlBuilder) Args() []any {
 	return b.args
 }
 
+// redactedArgs returns the args for the logs, the sensitive args are masked by cfg.Redact.
+func (b *BaseSqlBuilder) redactedArgs(cfg *Cfg) []any {
+	if len(b.sensitiveArgs) == 0 {
+		return b.args
+	}
+	args := slices.Clone(b.args)
+	for i, column := range b.sensitiveArgs {
+		if cfg.Redact != nil {
+			args[i] = cfg.Redact(column, args[i])
+		} else {
+			args[i] = "***"
+		}
+	}
+	return args
+}
+
 func (b *BaseSqlBuilder) SetError(err error) {
 	if err != nil {
 		b.err = err
@@ -204,3 +372,35 @@ func (b *BaseSqlBuilder) WriteSuffix(s *Separate, n int) *BaseSqlBuilder {
 func NewBaseSqlBuilder() *BaseSqlBuilder {
 	return &BaseSqlBuilder{ok: true}
 }
+
+// sensitiveArg is an arg bound to a sensitive column.
+type sensitiveArg struct {
+	column string
+	value  any
+}
+
+// SensitiveArg marks value as an arg bound to the sensitive column, Write and SetArgs set value as the arg, and it
+// is redacted in the logs and *Error.
+func SensitiveArg(column string, value any) any {
+	if s, ok := value.(sensitiveArg); ok {
+		return s
+	}
+	return sensitiveArg{column: column, value: value}
+}
+
+// sensitiveKey identifies a field value by its type and address, so that it is recognized when it is set as an arg.
+type sensitiveKey struct {
+	t reflect.Type
+	p uintptr
+}
+
+func sensitiveKeyOf(v reflect.Value) (sensitiveKey, bool) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		if !v.IsNil() {
+			return sensitiveKey{t: v.Type(), p: v.Pointer()}, true
+		}
+	default:
+	}
+	return sensitiveKey{}, false
+}
@@ -0,0 +1,269 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores the query results, the entries are grouped by the table of the dao, and all entries of a table are
+// invalidated after a statement is executed by the dao.
+type Cache interface {
+	Get(table, key string) (value any, ok bool)
+	Set(table, key string, value any, ttl time.Duration)
+	Invalidate(table string)
+}
+
+type lruEntry struct {
+	table, key string
+	value      any
+	expireAt   time.Time
+}
+
+// LRUCache is an in-memory Cache, the least recently used entry is evicted when it is full.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	tables   map[string]map[string]*list.Element
+}
+
+func (c *LRUCache) Get(table, key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.tables[table][key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if time.Now().After(entry.expireAt) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.ll.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (c *LRUCache) Set(table, key string, value any, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expireAt := time.Now().Add(ttl)
+	if elem, ok := c.tables[table][key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value, entry.expireAt = value, expireAt
+		c.ll.MoveToFront(elem)
+		return
+	}
+	keys, ok := c.tables[table]
+	if !ok {
+		keys = make(map[string]*list.Element)
+		c.tables[table] = keys
+	}
+	keys[key] = c.ll.PushFront(&lruEntry{table: table, key: key, value: value, expireAt: expireAt})
+	for c.capacity > 0 && c.ll.Len() > c.capacity {
+		c.remove(c.ll.Back())
+	}
+}
+
+func (c *LRUCache) Invalidate(table string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, elem := range c.tables[table] {
+		c.ll.Remove(elem)
+	}
+	delete(c.tables, table)
+}
+
+// Len returns the number of the entries, including the expired ones which are not evicted yet.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *LRUCache) remove(elem *list.Element) {
+	entry := c.ll.Remove(elem).(*lruEntry)
+	keys := c.tables[entry.table]
+	delete(keys, entry.key)
+	if len(keys) == 0 {
+		delete(c.tables, entry.table)
+	}
+}
+
+// NewLRUCache creates a LRUCache holding at most capacity entries, 0 means unlimited.
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{capacity: capacity, ll: list.New(), tables: make(map[string]map[string]*list.Element)}
+}
+
+// cacheKey identifies a query by the *sql.DB, the SQL and the values of the args.
+func (d baseDao) cacheKey(ctx context.Context, sql string, args []any) string {
+	var key strings.Builder
+	fmt.Fprintf(&key, "%p|%s", d.dbOf(ctx), sql)
+	for _, a := range args {
+		if v := reflect.ValueOf(a); v.Kind() == reflect.Pointer && !v.IsNil() {
+			a = v.Elem().Interface()
+		}
+		fmt.Fprintf(&key, "|%#v", a)
+	}
+	return key.String()
+}
+
+// boundWrites holds the tables written in the transactions bound by SetTx, SetDBTx or SetRollbackTx. Their commits
+// are not visible to gdao, so the tables are not cached until BoundTxDone is called with the transactions.
+var boundWrites = struct {
+	sync.Mutex
+	txs map[*sql.Tx][]boundWrite
+	// the number of pending writes of each table
+	tables map[string]int
+}{txs: make(map[*sql.Tx][]boundWrite), tables: make(map[string]int)}
+
+type boundWrite struct {
+	table string
+	cache Cache
+}
+
+// cacheable reports whether the query result can be read from or written to the cache. The daos without a table are
+// never cached, because the entries are grouped by the table. The queries in a transaction are not cached, because
+// they may see the uncommitted changes. Neither are the queries of a table written by a transaction bound by SetTx,
+// SetDBTx or SetRollbackTx before BoundTxDone is called.
+func (d baseDao) cacheable(ctx context.Context, ttl time.Duration) bool {
+	return ttl > 0 && d.table != "" && d.config().Cache != nil && lookupTxCtx(ctx, d.dbOf(ctx)) == nil && !d.pendingBoundWrite()
+}
+
+// pendingBoundWrite reports whether the table of the dao is written by a bound transaction not reported done.
+func (d baseDao) pendingBoundWrite() bool {
+	boundWrites.Lock()
+	defer boundWrites.Unlock()
+	return boundWrites.tables[d.table] > 0
+}
+
+// endBoundWrites invalidates the cache of the tables written in tx, and allows them to be cached again.
+func endBoundWrites(tx *sql.Tx) {
+	boundWrites.Lock()
+	defer boundWrites.Unlock()
+	for _, w := range boundWrites.txs[tx] {
+		w.cache.Invalidate(w.table)
+		if boundWrites.tables[w.table]--; boundWrites.tables[w.table] <= 0 {
+			delete(boundWrites.tables, w.table)
+		}
+	}
+	delete(boundWrites.txs, tx)
+}
+
+// invalidateCache invalidates the cache entries of the table of the dao. In a transaction begun by Tx, it is deferred
+// until the transaction is committed. The commit of a transaction bound by SetTx, SetDBTx or SetRollbackTx is not
+// visible, so the entries are invalidated at once, and again by BoundTxDone, in between the table is not cached.
+func (d baseDao) invalidateCache(ctx context.Context) {
+	cache := d.config().Cache
+	if cache == nil || d.table == "" {
+		return
+	}
+	if tc := lookupTxCtx(ctx, d.dbOf(ctx)); tc != nil {
+		root := tc
+		for root.parent != nil {
+			root = root.parent
+		}
+		if !root.bound {
+			tc.mu.Lock()
+			defer tc.mu.Unlock()
+			tc.afterCommits = append(tc.afterCommits, func() { cache.Invalidate(d.table) })
+			return
+		}
+		boundWrites.Lock()
+		boundWrites.txs[root.tx] = append(boundWrites.txs[root.tx], boundWrite{table: d.table, cache: cache})
+		boundWrites.tables[d.table]++
+		boundWrites.Unlock()
+	}
+	cache.Invalidate(d.table)
+}
+
+// copyEntities deep copies the entities, so that the cached entities are not modified by the callers.
+func copyEntities[T any](list []*T) []*T {
+	copied := make([]*T, len(list))
+	for i, entity := range list {
+		if entity != nil {
+			copied[i] = deepCopy(reflect.ValueOf(entity)).Interface().(*T)
+		}
+	}
+	return copied
+}
+
+// deepCopy copies v with the pointers, slices, maps and exported struct fields in it. The unexported fields are shared,
+// e.g. the *big.Int of Decimal and the location of time.Time, which are never modified in place.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+		switch v.Type().Elem().Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+			for i := range v.Len() {
+				c.Index(i).Set(deepCopy(v.Index(i)))
+			}
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := range v.Len() {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := range v.NumField() {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	}
+	return v
+}
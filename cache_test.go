@@ -0,0 +1,244 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jishaocong0910/gdao"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	r := require.New(t)
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+	cache := gdao.NewLRUCache(10)
+	gdao.Config(gdao.Cfg{DefaultDB: db, Cache: cache})
+	dao := gdao.DaoBuilder[User]().Table("user").Build()
+	countDao := gdao.CountDaoBuilder().Table("user").Build()
+	list := func(ctx context.Context, id int32) (*User, error) {
+		user, _, err := dao.Query().Ctx(ctx).Cache(time.Minute).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("SELECT id, name FROM user WHERE id=?", gdao.P(id))
+		}).Do()
+		return user, err
+	}
+	count := func(ctx context.Context) int {
+		c, err := countDao.Count().Ctx(ctx).Cache(time.Minute).BuildSql(func(b *gdao.CountBuilder) {
+			b.Write("SELECT COUNT(*) FROM user")
+		}).Do()
+		r.NoError(err)
+		return c.Int()
+	}
+	update := func(ctx context.Context) {
+		_, err := dao.Exec().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("UPDATE user SET name=? WHERE id=?", "bar", 1)
+		}).Do()
+		r.NoError(err)
+	}
+	{
+		mock.ExpectPrepare(`SELECT id, name FROM user WHERE id=\?`).ExpectQuery().WithArgs(1).
+			WillReturnRows(mock.NewRows([]string{"id", "name"}).AddRow(1, "foo"))
+		mock.ExpectPrepare(`SELECT COUNT\(\*\) FROM user`).ExpectQuery().WillReturnRows(mock.NewRows([]string{"count"}).AddRow(5))
+		user, err := list(nil, 1)
+		r.NoError(err)
+		r.Equal(5, count(nil))
+		user.Name = gdao.P("modified")
+
+		user, err = list(nil, 1)
+		r.NoError(err)
+		r.Equal("foo", *user.Name)
+		r.Equal(5, count(nil))
+		r.Equal(2, cache.Len())
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		mock.ExpectPrepare(`UPDATE user SET name=\? WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		update(nil)
+		r.Equal(0, cache.Len())
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		mock.ExpectPrepare(`SELECT id, name FROM user WHERE id=\?`).ExpectQuery().WithArgs(2).
+			WillReturnRows(mock.NewRows([]string{"id", "name"}).AddRow(2, "foo"))
+		mock.ExpectBegin()
+		mock.ExpectPrepare(`SELECT id, name FROM user WHERE id=\?`).ExpectQuery().WithArgs(2).
+			WillReturnRows(mock.NewRows([]string{"id", "name"}).AddRow(2, "foo"))
+		mock.ExpectPrepare(`UPDATE user SET name=\? WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		_, err = list(nil, 2)
+		r.NoError(err)
+		r.NoError(gdao.Tx(nil, func(ctx context.Context) error {
+			_, err := list(ctx, 2)
+			r.NoError(err)
+			update(ctx)
+			r.Equal(1, cache.Len())
+			return nil
+		}))
+		r.Equal(0, cache.Len())
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		mock.ExpectPrepare(`SELECT id, name FROM user WHERE id=\?`).ExpectQuery().WithArgs(3).
+			WillReturnRows(mock.NewRows([]string{"id", "name"}).AddRow(3, "foo"))
+		mock.ExpectBegin()
+		mock.ExpectPrepare(`UPDATE user SET name=\? WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+		_, err = list(nil, 3)
+		r.NoError(err)
+		r.Error(gdao.Tx(nil, func(ctx context.Context) error {
+			update(ctx)
+			return errors.New("rollback")
+		}))
+		r.Equal(1, cache.Len())
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		mock.ExpectPrepare(`SELECT id, name FROM user WHERE id=\?`).ExpectQuery().WithArgs(4).
+			WillReturnRows(mock.NewRows([]string{"id", "name"}).AddRow(4, "foo"))
+		user, err := list(nil, 4)
+		r.NoError(err)
+		*user.Name = "modified"
+		user, err = list(nil, 4)
+		r.NoError(err)
+		r.Equal("foo", *user.Name)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		mock.ExpectBegin()
+		mock.ExpectPrepare(`UPDATE user SET name=\? WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		for range 2 {
+			mock.ExpectPrepare(`SELECT id, name FROM user WHERE id=\?`).ExpectQuery().WithArgs(5).
+				WillReturnRows(mock.NewRows([]string{"id", "name"}).AddRow(5, "foo"))
+		}
+		mock.ExpectCommit()
+		for range 2 {
+			mock.ExpectPrepare(`SELECT id, name FROM user WHERE id=\?`).ExpectQuery().WithArgs(5).
+				WillReturnRows(mock.NewRows([]string{"id", "name"}).AddRow(5, "bar"))
+		}
+		tx, err := db.Begin()
+		r.NoError(err)
+		update(gdao.SetDBTx(nil, db, tx))
+		r.Equal(0, cache.Len())
+		for range 2 {
+			_, err = list(nil, 5)
+			r.NoError(err)
+			r.Equal(0, cache.Len())
+		}
+		r.NoError(tx.Commit())
+		user, err := list(nil, 5)
+		r.NoError(err)
+		r.Equal(0, cache.Len())
+		gdao.BoundTxDone(tx)
+		user, err = list(nil, 5)
+		r.NoError(err)
+		r.Equal("bar", *user.Name)
+		user, err = list(nil, 5)
+		r.NoError(err)
+		r.Equal("bar", *user.Name)
+		r.Equal(1, cache.Len())
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		// the daos without a table are not cached, and their writes do not affect the others
+		noTable := gdao.DaoBuilder[User]().Build()
+		mock.ExpectBegin()
+		mock.ExpectPrepare(`UPDATE user SET name=\? WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		for range 2 {
+			mock.ExpectPrepare(`SELECT id, name FROM user WHERE id=\?`).ExpectQuery().WithArgs(6).
+				WillReturnRows(mock.NewRows([]string{"id", "name"}).AddRow(6, "foo"))
+		}
+		tx, err := db.Begin()
+		r.NoError(err)
+		_, err = noTable.Exec().Ctx(gdao.SetDBTx(nil, db, tx)).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("UPDATE user SET name=? WHERE id=?", "bar", 1)
+		}).Do()
+		r.NoError(err)
+		r.Equal(1, cache.Len())
+		for range 2 {
+			_, _, err = noTable.Query().Cache(time.Minute).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+				b.Write("SELECT id, name FROM user WHERE id=?", 6)
+			}).Do()
+			r.NoError(err)
+		}
+		r.Equal(1, cache.Len())
+		_, err = list(nil, 5)
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+}
+
+func TestCache_DeepCopy(t *testing.T) {
+	r := require.New(t)
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+	gdao.Config(gdao.Cfg{DefaultDB: db, Cache: gdao.NewLRUCache(10)})
+	dao := gdao.DaoBuilder[Customer]().Table("customer").Build()
+	get := func() *Customer {
+		customer, _, err := dao.Query().Cache(time.Minute).BuildSql(func(b *gdao.DaoSqlBuilder[Customer]) {
+			b.Write("SELECT profile, tags, attrs FROM customer")
+		}).Do()
+		r.NoError(err)
+		return customer
+	}
+	mock.ExpectPrepare(`SELECT profile, tags, attrs FROM customer`).ExpectQuery().
+		WillReturnRows(mock.NewRows([]string{"profile", "tags", "attrs"}).AddRow(`{"nickname":"foo"}`, `["a"]`, `{"k":"v"}`))
+	customer := get()
+	customer.Profile.Nickname = "bar"
+	customer.Tags[0] = "b"
+	customer.Attrs["k"] = "w"
+
+	customer = get()
+	r.Equal(&Profile{Nickname: "foo"}, customer.Profile)
+	r.Equal([]string{"a"}, customer.Tags)
+	r.Equal(map[string]string{"k": "v"}, customer.Attrs)
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func TestLRUCache(t *testing.T) {
+	r := require.New(t)
+	cache := gdao.NewLRUCache(2)
+	cache.Set("user", "a", 1, time.Minute)
+	cache.Set("user", "b", 2, time.Minute)
+	_, ok := cache.Get("user", "a")
+	r.True(ok)
+	cache.Set("account", "c", 3, time.Minute)
+	_, ok = cache.Get("user", "b")
+	r.False(ok)
+	v, ok := cache.Get("user", "a")
+	r.True(ok)
+	r.Equal(1, v)
+
+	cache.Set("account", "c", 4, time.Minute)
+	v, _ = cache.Get("account", "c")
+	r.Equal(4, v)
+	cache.Set("account", "d", 5, -time.Second)
+	_, ok = cache.Get("account", "d")
+	r.False(ok)
+	r.Equal(1, cache.Len())
+
+	cache.Set("user", "a", 1, time.Minute)
+	cache.Invalidate("user")
+	_, ok = cache.Get("user", "a")
+	r.False(ok)
+	_, ok = cache.Get("account", "c")
+	r.True(ok)
+}
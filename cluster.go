@@ -0,0 +1,211 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ctx_key_use_primary = P("")
+
+// DBResolver resolves the *sql.DB of a dao, the statements in transactions and the writing statements are executed on
+// the primary, and the reading statements are executed on the replica, a nil replica means the primary.
+type DBResolver interface {
+	Primary() *sql.DB
+	Replica(ctx context.Context) *sql.DB
+}
+
+// ReplicaPolicy chooses one of the available replicas, replicas is never empty.
+type ReplicaPolicy interface {
+	Choose(replicas []*sql.DB) *sql.DB
+}
+
+type ReplicaPolicyFunc func(replicas []*sql.DB) *sql.DB
+
+func (f ReplicaPolicyFunc) Choose(replicas []*sql.DB) *sql.DB {
+	return f(replicas)
+}
+
+func RandomPolicy() ReplicaPolicy {
+	return ReplicaPolicyFunc(func(replicas []*sql.DB) *sql.DB {
+		return replicas[rand.IntN(len(replicas))]
+	})
+}
+
+func RoundRobinPolicy() ReplicaPolicy {
+	var next atomic.Uint64
+	return ReplicaPolicyFunc(func(replicas []*sql.DB) *sql.DB {
+		return replicas[(next.Add(1)-1)%uint64(len(replicas))]
+	})
+}
+
+func LeastConnsPolicy() ReplicaPolicy {
+	return ReplicaPolicyFunc(func(replicas []*sql.DB) *sql.DB {
+		least := replicas[0]
+		inUse := least.Stats().InUse
+		for _, r := range replicas[1:] {
+			if n := r.Stats().InUse; n < inUse {
+				least, inUse = r, n
+			}
+		}
+		return least
+	})
+}
+
+// UsePrimary makes the reading statements executed on the primary, it is used for the read-after-write consistency.
+func UsePrimary(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, ctx_key_use_primary, true)
+}
+
+func isUsePrimary(ctx context.Context) bool {
+	if ctx != nil {
+		usePrimary, _ := ctx.Value(ctx_key_use_primary).(bool)
+		return usePrimary
+	}
+	return false // coverage-ignore
+}
+
+type Cluster struct {
+	primary  *sql.DB
+	replicas []*replica
+	policy   ReplicaPolicy
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type replica struct {
+	db        *sql.DB
+	unhealthy atomic.Bool
+}
+
+func (c *Cluster) Primary() *sql.DB {
+	return c.primary
+}
+
+func (c *Cluster) Replica(context.Context) *sql.DB {
+	available := make([]*sql.DB, 0, len(c.replicas))
+	for _, r := range c.replicas {
+		if !r.unhealthy.Load() {
+			available = append(available, r.db)
+		}
+	}
+	if len(available) == 0 {
+		return nil
+	}
+	return c.policy.Choose(available)
+}
+
+// Close stops the health check.
+func (c *Cluster) Close() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+// checkHealth pings the replicas, the failed replicas are dropped from rotation until they are pinged successfully.
+func (c *Cluster) checkHealth(timeout time.Duration) {
+	var wg sync.WaitGroup
+	for _, r := range c.replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			err := r.db.PingContext(ctx)
+			if err != nil {
+				if !r.unhealthy.Swap(true) {
+					printWarn(ctx, fmt.Errorf("replica is dropped from rotation: %w", err))
+				}
+			} else {
+				r.unhealthy.Store(false)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (c *Cluster) runHealthCheck(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.checkHealth(interval)
+		}
+	}
+}
+
+type clusterBuilder struct {
+	primary             *sql.DB
+	replicas            []*sql.DB
+	policy              ReplicaPolicy
+	healthCheckInterval time.Duration
+}
+
+func (b *clusterBuilder) Primary(primary *sql.DB) *clusterBuilder {
+	b.primary = primary
+	return b
+}
+
+func (b *clusterBuilder) Replicas(replicas ...*sql.DB) *clusterBuilder {
+	b.replicas = replicas
+	return b
+}
+
+// Policy specifies the ReplicaPolicy, default is RoundRobinPolicy.
+func (b *clusterBuilder) Policy(policy ReplicaPolicy) *clusterBuilder {
+	b.policy = policy
+	return b
+}
+
+// HealthCheck pings the replicas every interval, 0 means no health check.
+func (b *clusterBuilder) HealthCheck(interval time.Duration) *clusterBuilder {
+	b.healthCheckInterval = interval
+	return b
+}
+
+func (b *clusterBuilder) Build() *Cluster {
+	if b.primary == nil {
+		panic("primary must not be nil")
+	}
+	c := &Cluster{primary: b.primary, policy: b.policy, stop: make(chan struct{})}
+	if c.policy == nil {
+		c.policy = RoundRobinPolicy()
+	}
+	for _, db := range b.replicas {
+		c.replicas = append(c.replicas, &replica{db: db})
+	}
+	if b.healthCheckInterval > 0 && len(c.replicas) > 0 {
+		go c.runHealthCheck(b.healthCheckInterval)
+	}
+	return c
+}
+
+func ClusterBuilder() *clusterBuilder {
+	return &clusterBuilder{}
+}
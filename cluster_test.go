@@ -0,0 +1,128 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jishaocong0910/gdao"
+	"github.com/stretchr/testify/require"
+)
+
+func mockCluster(r *require.Assertions, policy gdao.ReplicaPolicy) (*gdao.Cluster, sqlmock.Sqlmock, []sqlmock.Sqlmock) {
+	primary, primaryMock, err := sqlmock.New()
+	r.NoError(err)
+	var replicas []*sql.DB
+	var replicaMocks []sqlmock.Sqlmock
+	for i := 0; i < 2; i++ {
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		r.NoError(err)
+		replicas = append(replicas, db)
+		replicaMocks = append(replicaMocks, mock)
+	}
+	c := gdao.ClusterBuilder().Primary(primary).Replicas(replicas...).Policy(policy).Build()
+	return c, primaryMock, replicaMocks
+}
+
+func TestCluster(t *testing.T) {
+	r := require.New(t)
+	query := func(ctx context.Context, dao *gdao.Dao[User]) {
+		_, _, err := dao.Query().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("SELECT id FROM user")
+		}).Do()
+		r.NoError(err)
+	}
+	expectQuery := func(mock sqlmock.Sqlmock) {
+		mock.ExpectPrepare(`SELECT id FROM user`).ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	}
+	{
+		c, primaryMock, replicaMocks := mockCluster(r, nil)
+		dao := gdao.DaoBuilder[User]().Resolver(c).Build()
+		r.Equal(c.Primary(), dao.DB())
+		expectQuery(replicaMocks[0])
+		expectQuery(replicaMocks[1])
+		expectQuery(replicaMocks[0])
+		query(nil, dao)
+		query(nil, dao)
+		query(nil, dao)
+
+		expectQuery(primaryMock)
+		query(gdao.UsePrimary(nil), dao)
+
+		primaryMock.ExpectPrepare(`UPDATE user SET status=1`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		_, err := dao.Exec().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("UPDATE user SET status=1")
+		}).Do()
+		r.NoError(err)
+
+		primaryMock.ExpectBegin()
+		expectQuery(primaryMock)
+		primaryMock.ExpectCommit()
+		err = gdao.Tx(nil, func(ctx context.Context) error {
+			query(ctx, dao)
+			return nil
+		}, gdao.WithDefaultTx(dao.DB(), nil))
+		r.NoError(err)
+
+		countDao := gdao.CountDaoBuilder().Resolver(c).Build()
+		replicaMocks[1].ExpectPrepare(`SELECT COUNT\(\*\) FROM user`).ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		count, err := countDao.Count().BuildSql(func(b *gdao.CountBuilder) {
+			b.Write("SELECT COUNT(*) FROM user")
+		}).Do()
+		r.NoError(err)
+		r.Equal(3, count.Int())
+
+		r.NoError(primaryMock.ExpectationsWereMet())
+		r.NoError(replicaMocks[0].ExpectationsWereMet())
+		r.NoError(replicaMocks[1].ExpectationsWereMet())
+	}
+	{
+		c, primaryMock, replicaMocks := mockCluster(r, gdao.RandomPolicy())
+		dao := gdao.DaoBuilder[User]().Resolver(c).Build()
+		replicaMocks[0].ExpectPing().WillReturnError(errors.New("down"))
+		replicaMocks[1].ExpectPing().WillReturnError(errors.New("down"))
+		gdao.CheckHealth(c)
+		r.Nil(c.Replica(nil))
+		expectQuery(primaryMock)
+		query(nil, dao)
+
+		replicaMocks[0].ExpectPing().WillReturnError(errors.New("down"))
+		replicaMocks[1].ExpectPing()
+		gdao.CheckHealth(c)
+		expectQuery(replicaMocks[1])
+		query(nil, dao)
+
+		r.NoError(primaryMock.ExpectationsWereMet())
+		r.NoError(replicaMocks[0].ExpectationsWereMet())
+		r.NoError(replicaMocks[1].ExpectationsWereMet())
+		c.Close()
+		c.Close()
+	}
+	{
+		c, _, _ := mockCluster(r, gdao.LeastConnsPolicy())
+		r.NotNil(c.Replica(nil))
+	}
+	{
+		r.PanicsWithValue("primary must not be nil", func() {
+			gdao.ClusterBuilder().Build()
+		})
+	}
+}
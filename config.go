@@ -16,17 +16,119 @@
 
 package gdao
 
-import "database/sql"
+import (
+	"database/sql"
+	"sync/atomic"
+	"time"
+)
 
 type Cfg struct {
 	DefaultDB      *sql.DB
 	Logger         Logger
 	LogLevel       LogLevel
 	CompressSqlLog bool
+	// if true, the SQL logs inline the args as the literals of the dialect instead of listing them, it is only for
+	// displaying, see Dialect.InlineSql.
+	InlineSqlLog bool
+	// the transactions taking longer than it are logged as warnings, 0 means no warning.
+	SlowTxThreshold time.Duration
+	// the default timeout of each statement, 0 means no timeout.
+	Timeout time.Duration
+	// if true, the args of *Error are masked as "***".
+	RedactErrorArgs bool
+	// masks the args bound to the sensitive columns in the logs and *Error, default returns "***".
+	Redact func(column string, arg any) any
+	// if true, the writing statements are logged and not executed.
+	DryRun bool
+	// if true, the SELECT, UPDATE and DELETE statements are explained in MySQL, PostgreSQL and SQLite, and a warning is
+	// logged if the plan has a full table scan or filesort. It is for development and testing.
+	Explain bool
+	// only the statements taking longer than it are explained.
+	ExplainThreshold time.Duration
+	// caches the results of the queries calling Cache, e.g. NewLRUCache(1000).
+	Cache Cache
+	// marshals and unmarshals the fields tagged json, default uses encoding/json.
+	JSONCodec JSONCodec
 }
 
-var global Cfg
+var global atomic.Pointer[Cfg]
 
+// Config sets the global config, it is safe to call concurrently with the executing statements.
 func Config(cfg Cfg) {
-	global = cfg
+	global.Store(&cfg)
+}
+
+// globalCfg returns the global config, it must not be modified.
+func globalCfg() *Cfg {
+	if cfg := global.Load(); cfg != nil {
+		return cfg
+	}
+	return &Cfg{}
+}
+
+// CfgOverride is the config of a DAO overriding the global config. The nil fields and the undefined LogLevel follow
+// the global config, the others override it, e.g. DryRun: P(false) turns off the DryRun of the global config.
+// SlowTxThreshold is absent because it is for the transactions.
+type CfgOverride struct {
+	DefaultDB        *sql.DB
+	Logger           Logger
+	LogLevel         LogLevel
+	CompressSqlLog   *bool
+	InlineSqlLog     *bool
+	Timeout          *time.Duration
+	RedactErrorArgs  *bool
+	Redact           func(column string, arg any) any
+	DryRun           *bool
+	Explain          *bool
+	ExplainThreshold *time.Duration
+	Cache            Cache
+	JSONCodec        JSONCodec
+}
+
+// override returns a copy of c overridden by the set fields of o, c is returned if o is nil.
+func (c *Cfg) override(o *CfgOverride) *Cfg {
+	if o == nil {
+		return c
+	}
+	cfg := *c
+	if o.DefaultDB != nil {
+		cfg.DefaultDB = o.DefaultDB
+	}
+	if o.Logger != nil {
+		cfg.Logger = o.Logger
+	}
+	if !o.LogLevel.IsUndefined() {
+		cfg.LogLevel = o.LogLevel
+	}
+	if o.CompressSqlLog != nil {
+		cfg.CompressSqlLog = *o.CompressSqlLog
+	}
+	if o.InlineSqlLog != nil {
+		cfg.InlineSqlLog = *o.InlineSqlLog
+	}
+	if o.Timeout != nil {
+		cfg.Timeout = *o.Timeout
+	}
+	if o.RedactErrorArgs != nil {
+		cfg.RedactErrorArgs = *o.RedactErrorArgs
+	}
+	if o.Redact != nil {
+		cfg.Redact = o.Redact
+	}
+	if o.DryRun != nil {
+		cfg.DryRun = *o.DryRun
+	}
+	if o.Explain != nil {
+		cfg.Explain = *o.Explain
+	}
+	if o.ExplainThreshold != nil {
+		cfg.ExplainThreshold = *o.ExplainThreshold
+	}
+	if o.Cache != nil {
+		cfg.Cache = o.Cache
+	}
+	if o.JSONCodec != nil {
+		cfg.JSONCodec = o.JSONCodec
+	}
+	return &cfg
 }
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao_test
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jishaocong0910/gdao"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDaoBuilder_Config(t *testing.T) {
+	r := require.New(t)
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+	global, own := &MockLogger{}, &MockLogger{}
+	gdao.Config(gdao.Cfg{DefaultDB: db, Logger: global, LogLevel: gdao.LogLevel_.DEBUG})
+	dao := gdao.DaoBuilder[User]().Config(gdao.CfgOverride{Logger: own, InlineSqlLog: gdao.P(true)}).Build()
+	countDao := gdao.CountDaoBuilder().Config(gdao.CfgOverride{DryRun: gdao.P(true)}).Build()
+
+	mock.ExpectPrepare(`SELECT id FROM user WHERE id=\?`).ExpectQuery().WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	_, _, err = dao.Query().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+		b.Write("SELECT id FROM user WHERE id=?", 1)
+	}).Do()
+	r.NoError(err)
+	r.Nil(global.args)
+	r.Equal("SQL (inlined, display only): %s; row counts: %d", own.msg)
+	r.Equal("SELECT id FROM user WHERE id=1", own.args[0])
+
+	mock.ExpectPrepare(`SELECT COUNT\(\*\) FROM user`).ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	count, err := countDao.Count().BuildSql(func(b *gdao.CountBuilder) {
+		b.Write("SELECT COUNT(*) FROM user")
+	}).Do()
+	r.NoError(err)
+	r.Equal(int64(3), *count.Value)
+	r.Equal("SQL: %s; row counts: %d", global.msg)
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func TestDaoBuilder_ConfigDisable(t *testing.T) {
+	r := require.New(t)
+	db, err := sql.Open("sqlite3", ":memory:")
+	r.NoError(err)
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec("CREATE TABLE user (id INTEGER PRIMARY KEY, name TEXT, status INTEGER)")
+	r.NoError(err)
+	log := &MockLogger{}
+	gdao.Config(gdao.Cfg{DefaultDB: db, Logger: log, LogLevel: gdao.LogLevel_.DEBUG, CompressSqlLog: true, InlineSqlLog: true,
+		Timeout: time.Nanosecond, RedactErrorArgs: true, DryRun: true, Explain: true, ExplainThreshold: time.Hour})
+	update := func(dao *gdao.Dao[User]) (int64, error) {
+		return dao.Exec().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("UPDATE user\nSET status=? WHERE status=?", 1, 0)
+		}).Do()
+	}
+	{
+		_, err = update(gdao.DaoBuilder[User]().Build())
+		r.NoError(err)
+		r.Equal("DRY RUN, SQL (inlined, display only): %s;", log.msg)
+		r.Equal("UPDATE user SET status=1 WHERE status=0", log.args[0])
+	}
+	off := gdao.DaoBuilder[User]().Config(gdao.CfgOverride{CompressSqlLog: gdao.P(false), InlineSqlLog: gdao.P(false),
+		Timeout: gdao.P(time.Duration(0)), RedactErrorArgs: gdao.P(false), DryRun: gdao.P(false), Explain: gdao.P(false),
+		ExplainThreshold: gdao.P(time.Duration(0))}).Build()
+	{
+		*log = MockLogger{}
+		_, err = update(off)
+		r.NoError(err)
+		r.Equal("SQL: %s; args: %v, affected: %d", log.msg)
+		r.Equal("UPDATE user\nSET status=? WHERE status=?", log.args[0])
+	}
+	{
+		_, err = off.Exec().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("INSERT INTO nothing(id) VALUES(?)", 1)
+		}).Do()
+		var ge *gdao.Error
+		r.ErrorAs(err, &ge)
+		r.NotErrorIs(err, gdao.ErrTimeout)
+		r.Equal([]any{1}, ge.Args)
+	}
+	{
+		*log = MockLogger{}
+		_, err = update(gdao.DaoBuilder[User]().Config(gdao.CfgOverride{InlineSqlLog: gdao.P(false), Timeout: gdao.P(time.Duration(0)),
+			DryRun: gdao.P(false), ExplainThreshold: gdao.P(time.Duration(0))}).Build())
+		r.NoError(err)
+		r.Equal("EXPLAIN, SQL: %s; %s", log.msg)
+	}
+}
+
+func TestConfig_Concurrent(t *testing.T) {
+	r := require.New(t)
+	gdao.Config(gdao.Cfg{DryRun: true})
+	dao := gdao.DaoBuilder[User]().Build()
+	const n = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := range n {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			gdao.Config(gdao.Cfg{DryRun: true, CompressSqlLog: i%2 == 0, Timeout: time.Duration(i) * time.Second})
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := dao.Exec().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+				b.Write("DELETE FROM user")
+			}).Do()
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		r.NoError(err)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 )
 
 type CountDao struct {
@@ -35,6 +36,8 @@ type countReq struct {
 	must     bool
 	logLevel LogLevel
 	desc     string
+	timeout  time.Duration
+	cache    time.Duration
 	buildSql func(b *CountBuilder)
 }
 
@@ -63,23 +66,66 @@ func (c *count) Desc(desc string) *count {
 	return c
 }
 
+func (c *count) Timeout(timeout time.Duration) *count {
+	c.req.timeout = timeout
+	return c
+}
+
+// Cache caches the result for ttl if Cfg.Cache is set, the queries in a transaction are not cached.
+func (c *count) Cache(ttl time.Duration) *count {
+	c.req.cache = ttl
+	return c
+}
+
 func (c *count) BuildSql(buildSql func(b *CountBuilder)) *count {
 	c.req.buildSql = buildSql
 	return c
 }
 
+// ToSql builds the statement without executing it, the args are converted as they are executed. The SQL is empty if
+// the statement is not to be executed, see BaseSqlBuilder.Ok.
+func (c *count) ToSql() (sql string, args []any, err error) {
+	b := &CountBuilder{BaseSqlBuilder: NewBaseSqlBuilder()}
+	c.req.buildSql(b)
+	if err = b.Error(); err != nil {
+		return "", nil, err
+	}
+	if !b.Ok() {
+		return "", nil, nil
+	}
+	return b.Sql(), c.dao.bindArgs(c.req.ctx, b.Args()), nil
+}
+
 func (c *count) Do() (count *Count, err error) {
+	cfg := c.dao.config()
 	b := &CountBuilder{BaseSqlBuilder: NewBaseSqlBuilder()}
 	c.req.buildSql(b)
 	if !b.Ok() { // coverage-ignore
 		return nil, b.Error()
 	}
-	rows, columns, closeFunc, err := c.dao.query(c.req.ctx, b.Sql(), b.Args())
-	if err != nil { // coverage-ignore
-		printSql(c.req.ctx, c.req.logLevel, c.req.desc, b.Sql(), b.Args(), -1, -1, err)
+	if cp := getSqlCapture(c.req.ctx); cp != nil {
+		cp.capture(b.Sql(), c.dao.bindArgs(c.req.ctx, b.Args()))
+		return nil, nil
+	}
+	var cacheKey string
+	if c.dao.cacheable(c.req.ctx, c.req.cache) {
+		cacheKey = c.dao.cacheKey(c.req.ctx, b.Sql(), b.Args())
+		if cached, ok := cfg.Cache.Get(c.dao.table, cacheKey); ok {
+			if value, ok := cached.(*int64); ok {
+				cfg.printMarkedSql(c.req.ctx, c.dao.dbOf(c.req.ctx), "CACHE", c.req.logLevel, c.req.desc, b.Sql(), b.redactedArgs(cfg), -1, 1, nil)
+				return &Count{Value: P(*value)}, nil
+			}
+		}
+	}
+	start := time.Now()
+	rows, columns, closeFunc, err := c.dao.query(c.req.ctx, b.Sql(), b.Args(), false, c.req.timeout)
+	if err != nil {
+		err = c.dao.newError(c.req.ctx, Op_.COUNT, c.req.desc, b.Sql(), b.redactedArgs(cfg), start, err)
+		cfg.printSql(c.req.ctx, c.dao.dbOf(c.req.ctx), c.req.logLevel, c.req.desc, b.Sql(), b.redactedArgs(cfg), -1, -1, err)
 		checkMust(c.req.must, err)
 		return nil, err
 	}
+	defer c.dao.explain(c.req.ctx, c.req.desc, b.Sql(), b.Args(), start)
 	defer closeFunc()
 
 	var rowCounts int64
@@ -91,12 +137,13 @@ func (c *count) Do() (count *Count, err error) {
 		count = &Count{}
 		if len(columns) > 1 {
 			count = nil
-			err = errors.New("returns more than one column")
+			err = c.dao.newError(c.req.ctx, Op_.COUNT, c.req.desc, b.Sql(), b.redactedArgs(cfg), start, errors.New("returns more than one column"))
 			checkMust(c.req.must, err)
 			return
 		}
 		err = rows.Scan(&count.Value)
 		if err != nil { // coverage-ignore
+			err = c.dao.newError(c.req.ctx, Op_.COUNT, c.req.desc, b.Sql(), b.redactedArgs(cfg), start, err)
 			count = nil
 			checkMust(c.req.must, err)
 			return
@@ -105,12 +152,15 @@ func (c *count) Do() (count *Count, err error) {
 
 	if rowCounts > 1 {
 		count = nil
-		err = errors.New("returns more than one row")
-		printSql(c.req.ctx, c.req.logLevel, c.req.desc, b.Sql(), b.Args(), -1, rowCounts, err)
+		err = c.dao.newError(c.req.ctx, Op_.COUNT, c.req.desc, b.Sql(), b.redactedArgs(cfg), start, errors.New("returns more than one row"))
+		cfg.printSql(c.req.ctx, c.dao.dbOf(c.req.ctx), c.req.logLevel, c.req.desc, b.Sql(), b.redactedArgs(cfg), -1, rowCounts, err)
 		checkMust(c.req.must, err)
 		return count, err
 	}
-	printSql(c.req.ctx, c.req.logLevel, c.req.desc, b.Sql(), b.Args(), -1, rowCounts, nil)
+	cfg.printSql(c.req.ctx, c.dao.dbOf(c.req.ctx), c.req.logLevel, c.req.desc, b.Sql(), b.redactedArgs(cfg), -1, rowCounts, nil)
+	if cacheKey != "" && count != nil && count.Value != nil {
+		cfg.Cache.Set(c.dao.table, cacheKey, P(*count.Value), c.req.cache)
+	}
 	return
 }
 
@@ -212,7 +262,11 @@ func (c *Count) BoolPtr() *bool {
 }
 
 type countDaoBuilder struct {
-	db *sql.DB
+	db       *sql.DB
+	resolver DBResolver
+	timeout  time.Duration
+	table    string
+	cfg      *CfgOverride
 }
 
 func (b *countDaoBuilder) DB(db *sql.DB) *countDaoBuilder {
@@ -220,8 +274,30 @@ func (b *countDaoBuilder) DB(db *sql.DB) *countDaoBuilder {
 	return b
 }
 
+func (b *countDaoBuilder) Resolver(resolver DBResolver) *countDaoBuilder {
+	b.resolver = resolver
+	return b
+}
+
+func (b *countDaoBuilder) Timeout(timeout time.Duration) *countDaoBuilder {
+	b.timeout = timeout
+	return b
+}
+
+// Table sets the table whose cached results are shared with the Dao of the same table.
+func (b *countDaoBuilder) Table(table string) *countDaoBuilder {
+	b.table = table
+	return b
+}
+
+// Config sets the config of the dao, see daoBuilder.Config.
+func (b *countDaoBuilder) Config(cfg CfgOverride) *countDaoBuilder {
+	b.cfg = &cfg
+	return b
+}
+
 func (b *countDaoBuilder) Build() *CountDao {
-	return &CountDao{baseDao: newBaseDao(b.db)}
+	return &CountDao{baseDao: newBaseDao(b.db, b.resolver, b.timeout, b.table, b.cfg)}
 }
 
 func CountDaoBuilder() *countDaoBuilder {
@@ -19,10 +19,12 @@ package gdao
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"github.com/jishaocong0910/gdao/internal"
 	"reflect"
 	"strings"
+	"time"
 )
 
 type Dao[T any] struct {
@@ -32,9 +34,12 @@ type Dao[T any] struct {
 	columnToFieldIndex     map[string]int
 	columnToFieldConvertor map[string]fieldConvertor
 	fieldNameToColumn      map[string]string
+	primaryKeyColumns      []string
 	autoIncrementColumns   []string
 	autoIncrementStep      int64
 	autoIncrementConvert   func(id int64) reflect.Value
+	sensitiveColumns       map[string]bool
+	jsonColumns            map[string]bool
 }
 
 func (d *Dao[T]) Query() *query[T] {
@@ -49,22 +54,90 @@ func (d *Dao[T]) NameMap() map[string]string {
 	return d.fieldNameToColumn
 }
 
-func (d *Dao[T]) mappingScanFields(entity *T, columns []string) ([]any, []func()) {
+func (d *Dao[T]) ColumnOf(name string) (column string, ok bool) {
+	if _, ok = d.columnToFieldIndex[name]; ok {
+		return name, true
+	}
+	column, ok = d.fieldNameToColumn[name]
+	return
+}
+
+// ColumnValue returns the value of the column of entity, the pointer is dereferenced, nil means NULL or an unknown
+// column.
+func (d *Dao[T]) ColumnValue(entity *T, column string) any {
+	index, ok := d.columnToFieldIndex[column]
+	if !ok || entity == nil {
+		return nil
+	}
+	field := reflect.ValueOf(entity).Elem().Field(index)
+	if field.IsNil() {
+		return nil
+	}
+	if field.Kind() == reflect.Pointer {
+		return field.Elem().Interface()
+	}
+	return field.Interface()
+}
+
+// ClearColumn sets the field of the column of entity to nil.
+func (d *Dao[T]) ClearColumn(entity *T, column string) {
+	if index, ok := d.columnToFieldIndex[column]; ok && entity != nil {
+		reflect.ValueOf(entity).Elem().Field(index).SetZero()
+	}
+}
+
+// IsSensitive reports whether the column of name is tagged sensitive, name is a column or a field name.
+func (d *Dao[T]) IsSensitive(name string) bool {
+	column, ok := d.ColumnOf(name)
+	return ok && d.sensitiveColumns[column]
+}
+
+// IsJSON reports whether the column of name is tagged json, name is a column or a field name.
+func (d *Dao[T]) IsJSON(name string) bool {
+	column, ok := d.ColumnOf(name)
+	return ok && d.jsonColumns[column]
+}
+
+// JSONArg returns value as an arg marshalled by the JSONCodec, it is used for the columns tagged json. The values
+// which are not structs, maps or slices are returned as is, e.g. a JSON string.
+func (d *Dao[T]) JSONArg(value any) any {
+	switch value.(type) {
+	case jsonArg, sensitiveArg, driver.Valuer:
+		return value
+	}
+	if t := reflect.TypeOf(value); t == nil || t == reflect.TypeOf([]byte(nil)) || !isJSONType(t) && t.Kind() != reflect.Struct {
+		return value
+	}
+	return jsonArg{value: value, codec: d.config().jsonCodec()}
+}
+
+// mappingScanFields returns the scan destinations of the columns, the slice fields are scanned as the PostgreSQL arrays
+// if pgArrays is true.
+func (d *Dao[T]) mappingScanFields(entity *T, columns []string, pgArrays bool) ([]any, []func() error) {
 	v := reflect.ValueOf(entity).Elem()
 	dests := make([]any, 0, len(columns))
-	afterScans := make([]func(), 0, len(columns))
+	afterScans := make([]func() error, 0, len(columns))
 	for _, c := range columns {
 		if index, ok := d.columnToFieldIndex[c]; ok {
 			field := v.Field(index)
-			if fc, ok := d.columnToFieldConvertor[c]; ok {
+			if d.jsonColumns[c] {
+				var data []byte
+				dests = append(dests, &data)
+				afterScans = append(afterScans, func() error {
+					return unmarshalJSON(d.config().jsonCodec(), data, field)
+				})
+			} else if pgArrays && isPgArrayType(field.Type()) {
+				dests = append(dests, pgArray{v: field.Addr()})
+			} else if fc, ok := d.columnToFieldConvertor[c]; ok {
 				sc := fc.newScanDest()
 				dests = append(dests, sc.dest)
-				afterScans = append(afterScans, func() {
+				afterScans = append(afterScans, func() error {
 					v := sc.getValue()
 					f := fc.toField(v)
 					if f != nil {
 						field.Set(reflect.ValueOf(f))
 					}
+					return nil
 				})
 			} else {
 				dests = append(dests, field.Addr().Interface())
@@ -92,6 +165,16 @@ func (d *Dao[T]) registerEntity(b *daoBuilder[T]) error {
 		}
 		if !tf.Anonymous {
 			ft := tf.Type
+			if parseTag(tf).isJSON {
+				if isJSONType(ft) {
+					d.registerField(tf, b.columnMapper, nil)
+					continue
+				}
+				if !b.allowInvalidField {
+					return errors.New("field \"" + tf.Name + "\" of \"" + t.String() + "\" tagged json must be a pointer to struct, a map or a slice")
+				}
+				continue
+			}
 			switch internal.IsImplementConvert(ft) {
 			case 1:
 				fc := getFieldConvertor(ft)
@@ -103,7 +186,7 @@ func (d *Dao[T]) registerEntity(b *daoBuilder[T]) error {
 				}
 			}
 			if ft.Kind() == reflect.Pointer || ft.Kind() == reflect.Slice {
-				if internal.IsBaseType(ft.Elem()) {
+				if internal.IsBaseType(ft.Elem()) || internal.IsArrayType(ft) || ft == reflect.PointerTo(decimalType) {
 					d.registerField(tf, b.columnMapper, nil)
 					continue
 				}
@@ -138,6 +221,9 @@ func (d *Dao[T]) registerField(tf reflect.StructField, columnMapper *NameMapper,
 	d.commaColumns += column
 	d.columnToFieldIndex[column] = tf.Index[0]
 	d.fieldNameToColumn[tf.Name] = column
+	if t.isPrimaryKey {
+		d.primaryKeyColumns = append(d.primaryKeyColumns, column)
+	}
 	if t.isAutoIncrement {
 		if convertor := lastInsertIdConvertor_.OfString(tf.Type.Elem().String()); !convertor.IsUndefined() {
 			d.autoIncrementColumns = append(d.autoIncrementColumns, column)
@@ -145,6 +231,12 @@ func (d *Dao[T]) registerField(tf reflect.StructField, columnMapper *NameMapper,
 			d.autoIncrementConvert = convertor.convert
 		}
 	}
+	if t.isSensitive {
+		d.sensitiveColumns[column] = true
+	}
+	if t.isJSON {
+		d.jsonColumns[column] = true
+	}
 	if fieldConvertor != nil {
 		d.columnToFieldConvertor[column] = *fieldConvertor
 	}
@@ -157,6 +249,8 @@ type query[T any] struct {
 	must     bool
 	logLevel LogLevel
 	desc     string
+	timeout  time.Duration
+	cache    time.Duration
 	rowAs    RowAs
 	entities []*T
 	buildSql func(b *DaoSqlBuilder[T])
@@ -182,6 +276,18 @@ func (q *query[T]) Desc(desc string) *query[T] {
 	return q
 }
 
+// Timeout sets the timeout of the statement, it overrides the default timeout of the dao and the configured timeout.
+func (q *query[T]) Timeout(timeout time.Duration) *query[T] {
+	q.timeout = timeout
+	return q
+}
+
+// Cache caches the result for ttl if Cfg.Cache is set, the queries with RowAs or in a transaction are not cached.
+func (q *query[T]) Cache(ttl time.Duration) *query[T] {
+	q.cache = ttl
+	return q
+}
+
 func (q *query[T]) RowAs(rowAs RowAs) *query[T] {
 	q.rowAs = rowAs
 	return q
@@ -197,8 +303,23 @@ func (q *query[T]) BuildSql(buildSql func(b *DaoSqlBuilder[T])) *query[T] {
 	return q
 }
 
+// ToSql builds the statement without executing it, the args are converted as they are executed. The SQL is empty if
+// the statement is not to be executed, see BaseSqlBuilder.Ok.
+func (q *query[T]) ToSql() (sql string, args []any, err error) {
+	b := newDaoSqlBuilder(q.dao, q.entities)
+	q.buildSql(b)
+	if err = b.Error(); err != nil {
+		return "", nil, err
+	}
+	if !b.Ok() {
+		return "", nil, nil
+	}
+	return b.Sql(), q.dao.bindArgs(q.ctx, b.Args()), nil
+}
+
 func (q *query[T]) Do() (first *T, list []*T, err error) {
 	list = make([]*T, 0)
+	cfg := q.dao.config()
 	b := newDaoSqlBuilder(q.dao, q.entities)
 	q.buildSql(b)
 	err = b.Error()
@@ -209,12 +330,38 @@ func (q *query[T]) Do() (first *T, list []*T, err error) {
 	if !b.Ok() { // coverage-ignore
 		return
 	}
-	rows, columns, closeFunc, err := q.dao.query(q.ctx, b.Sql(), b.Args())
-	if err != nil { // coverage-ignore
-		printSql(q.ctx, q.logLevel, q.desc, b.Sql(), b.Args(), -1, -1, err)
+	if c := getSqlCapture(q.ctx); c != nil {
+		c.capture(b.Sql(), q.dao.bindArgs(q.ctx, b.Args()))
+		return
+	}
+	if cfg.DryRun && !q.rowAs.IsUndefined() {
+		cfg.printMarkedSql(q.ctx, q.dao.dbOf(q.ctx), "DRY RUN", q.logLevel, q.desc, b.Sql(), b.redactedArgs(cfg), -1, -1, nil)
+		return
+	}
+	var cacheKey string
+	if q.rowAs.IsUndefined() && q.dao.cacheable(q.ctx, q.cache) {
+		cacheKey = q.dao.cacheKey(q.ctx, b.Sql(), b.Args())
+		if cached, ok := cfg.Cache.Get(q.dao.table, cacheKey); ok {
+			if list, ok = cached.([]*T); ok {
+				list = copyEntities(list)
+				if len(list) > 0 {
+					first = list[0]
+				}
+				cfg.printMarkedSql(q.ctx, q.dao.dbOf(q.ctx), "CACHE", q.logLevel, q.desc, b.Sql(), b.redactedArgs(cfg), -1, int64(len(list)), nil)
+				return
+			}
+			list = make([]*T, 0)
+		}
+	}
+	start := time.Now()
+	rows, columns, closeFunc, err := q.dao.query(q.ctx, b.Sql(), b.Args(), !q.rowAs.IsUndefined(), q.timeout)
+	if err != nil {
+		err = q.dao.newError(q.ctx, Op_.QUERY, q.desc, b.Sql(), b.redactedArgs(cfg), start, err)
+		cfg.printSql(q.ctx, q.dao.dbOf(q.ctx), q.logLevel, q.desc, b.Sql(), b.redactedArgs(cfg), -1, -1, err)
 		checkMust(q.must, err)
 		return nil, nil, err
 	}
+	defer q.dao.explain(q.ctx, q.desc, b.Sql(), b.Args(), start)
 	defer closeFunc()
 
 	switch q.rowAs.String() {
@@ -234,17 +381,18 @@ func (q *query[T]) Do() (first *T, list []*T, err error) {
 				}
 			}
 			if len(fields) > 0 {
-				printWarn(q.ctx, rows.Scan(fields...))
+				cfg.printWarn(q.ctx, rows.Scan(fields...))
 			}
 			affected++
 		}
-		printSql(q.ctx, q.logLevel, q.desc, b.Sql(), b.Args(), affected, -1, nil)
+		cfg.printSql(q.ctx, q.dao.dbOf(q.ctx), q.logLevel, q.desc, b.Sql(), b.redactedArgs(cfg), affected, -1, nil)
+		q.dao.invalidateCache(q.ctx)
 	case RowAs_.LAST_ID.String():
 		var affected int64
 		var id *int64
 		if rows.Next() && len(columns) == 1 && len(q.dao.autoIncrementColumns) == 1 {
 			err = rows.Scan(&id)
-			printWarn(q.ctx, err)
+			cfg.printWarn(q.ctx, err)
 			if err != nil && rows.Next() { // coverage-ignore
 				id = nil
 			}
@@ -270,19 +418,26 @@ func (q *query[T]) Do() (first *T, list []*T, err error) {
 				}
 			}
 		}
-		printSql(q.ctx, q.logLevel, q.desc, b.Sql(), b.Args(), affected, -1, nil)
+		cfg.printSql(q.ctx, q.dao.dbOf(q.ctx), q.logLevel, q.desc, b.Sql(), b.redactedArgs(cfg), affected, -1, nil)
+		q.dao.invalidateCache(q.ctx)
 	default:
 		var rowCounts int64
+		pgArrays := DialectOf(q.dao.dbOf(q.ctx)).Is(Dialect_.POSTGRES)
 		for rows.Next() {
 			entity := new(T)
-			dests, afterScans := q.dao.mappingScanFields(entity, columns)
+			dests, afterScans := q.dao.mappingScanFields(entity, columns, pgArrays)
 			err = rows.Scan(dests...)
 			if err != nil {
+				err = q.dao.newError(q.ctx, Op_.QUERY, q.desc, b.Sql(), b.redactedArgs(cfg), start, err)
 				checkMust(q.must, err)
 				return
 			}
 			for _, after := range afterScans {
-				after()
+				if err = after(); err != nil {
+					err = q.dao.newError(q.ctx, Op_.QUERY, q.desc, b.Sql(), b.redactedArgs(cfg), start, err)
+					checkMust(q.must, err)
+					return
+				}
 			}
 			list = append(list, entity)
 			rowCounts++
@@ -290,7 +445,10 @@ func (q *query[T]) Do() (first *T, list []*T, err error) {
 		if len(list) > 0 {
 			first = list[0]
 		}
-		printSql(q.ctx, q.logLevel, q.desc, b.Sql(), b.Args(), -1, rowCounts, nil)
+		cfg.printSql(q.ctx, q.dao.dbOf(q.ctx), q.logLevel, q.desc, b.Sql(), b.redactedArgs(cfg), -1, rowCounts, nil)
+		if cacheKey != "" {
+			cfg.Cache.Set(q.dao.table, cacheKey, copyEntities(list), q.cache)
+		}
 	}
 	return
 }
@@ -301,6 +459,7 @@ type exec[T any] struct {
 	must           bool
 	logLevel       LogLevel
 	desc           string
+	timeout        time.Duration
 	lastInsertIdAs LastInsertIdAs
 	entities       []*T
 	buildSql       func(b *DaoSqlBuilder[T])
@@ -326,6 +485,11 @@ func (e *exec[T]) Desc(desc string) *exec[T] {
 	return e
 }
 
+func (e *exec[T]) Timeout(timeout time.Duration) *exec[T] {
+	e.timeout = timeout
+	return e
+}
+
 func (e *exec[T]) LastInsertIdAs(lastInsertIdAs LastInsertIdAs) *exec[T] {
 	e.lastInsertIdAs = lastInsertIdAs
 	return e
@@ -341,7 +505,22 @@ func (e *exec[T]) BuildSql(buildSql func(b *DaoSqlBuilder[T])) *exec[T] {
 	return e
 }
 
+// ToSql builds the statement without executing it, the args are converted as they are executed. The SQL is empty if
+// the statement is not to be executed, see BaseSqlBuilder.Ok.
+func (e *exec[T]) ToSql() (sql string, args []any, err error) {
+	b := newDaoSqlBuilder(e.dao, e.entities)
+	e.buildSql(b)
+	if err = b.Error(); err != nil {
+		return "", nil, err
+	}
+	if !b.Ok() {
+		return "", nil, nil
+	}
+	return b.Sql(), e.dao.bindArgs(e.ctx, b.Args()), nil
+}
+
 func (e *exec[T]) Do() (affected int64, err error) {
+	cfg := e.dao.config()
 	b := newDaoSqlBuilder(e.dao, e.entities)
 	e.buildSql(b)
 	err = b.Error()
@@ -352,17 +531,31 @@ func (e *exec[T]) Do() (affected int64, err error) {
 	if !b.Ok() { // coverage-ignore
 		return 0, nil
 	}
-	result, affected, err := e.dao.exec(e.ctx, b.Sql(), b.Args())
-	printSql(e.ctx, e.logLevel, e.desc, b.Sql(), b.Args(), affected, -1, err)
-	if err != nil { // coverage-ignore
+	if c := getSqlCapture(e.ctx); c != nil {
+		c.capture(b.Sql(), e.dao.bindArgs(e.ctx, b.Args()))
+		return 0, nil
+	}
+	if cfg.DryRun {
+		cfg.printMarkedSql(e.ctx, e.dao.dbOf(e.ctx), "DRY RUN", e.logLevel, e.desc, b.Sql(), b.redactedArgs(cfg), -1, -1, nil)
+		return 0, nil
+	}
+	start := time.Now()
+	result, affected, err := e.dao.exec(e.ctx, b.Sql(), b.Args(), e.timeout)
+	if err != nil {
+		err = e.dao.newError(e.ctx, Op_.EXEC, e.desc, b.Sql(), b.redactedArgs(cfg), start, err)
+	}
+	cfg.printSql(e.ctx, e.dao.dbOf(e.ctx), e.logLevel, e.desc, b.Sql(), b.redactedArgs(cfg), affected, -1, err)
+	if err != nil {
 		checkMust(e.must, err)
 		return
 	}
+	e.dao.invalidateCache(e.ctx)
+	e.dao.explain(e.ctx, e.desc, b.Sql(), b.Args(), start)
 
 	switch e.lastInsertIdAs.String() {
 	case LastInsertIdAs_.FIRST_ID.String():
 		id, err := result.LastInsertId()
-		printWarn(e.ctx, err)
+		cfg.printWarn(e.ctx, err)
 		if err == nil && len(e.entities) > 0 && len(e.dao.autoIncrementColumns) == 1 {
 			fieldIndex := e.dao.columnToFieldIndex[e.dao.autoIncrementColumns[0]]
 			for i, entity := range e.entities {
@@ -376,7 +569,7 @@ func (e *exec[T]) Do() (affected int64, err error) {
 		}
 	case LastInsertIdAs_.LAST_ID.String():
 		id, err := result.LastInsertId()
-		printWarn(e.ctx, err)
+		cfg.printWarn(e.ctx, err)
 		if err == nil && len(e.entities) > 0 && len(e.dao.autoIncrementColumns) == 1 {
 			fieldIndex := e.dao.columnToFieldIndex[e.dao.autoIncrementColumns[0]]
 			entityLength := len(e.entities)
@@ -485,7 +678,7 @@ func (this *DaoSqlBuilder[T]) ColumnValue(entity *T, column string) any {
 	if vf.IsNil() {
 		return nil
 	}
-	return vf.Interface()
+	return this.fieldValue(column, vf)
 }
 
 func (this *DaoSqlBuilder[T]) EachEntity(sep *Separate, handle func(n int, entity *T)) *DaoSqlBuilder[T] {
@@ -510,7 +703,7 @@ func (this *DaoSqlBuilder[T]) EachColumn(entity *T, sep *Separate, handle func(n
 		field := v.Field(fieldIndex)
 		var value any
 		if !field.IsNil() {
-			value = field.Interface()
+			value = this.fieldValue(column, field)
 		}
 		n++
 		this.WritePrefix(sep, n)
@@ -522,6 +715,29 @@ func (this *DaoSqlBuilder[T]) EachColumn(entity *T, sep *Separate, handle func(n
 	return
 }
 
+// fieldValue returns the value of the field as an arg, the field tagged json is marshalled by the JSONCodec.
+func (this *DaoSqlBuilder[T]) fieldValue(column string, field reflect.Value) any {
+	if this.dao.jsonColumns[column] {
+		var value any = jsonArg{value: field.Interface(), codec: this.dao.config().jsonCodec()}
+		if this.dao.sensitiveColumns[column] {
+			value = SensitiveArg(column, value)
+		}
+		return value
+	}
+	this.trackSensitive(column, field)
+	return field.Interface()
+}
+
+// trackSensitive remembers the value of a sensitive column, so that it is redacted when it is set as an arg.
+func (this *DaoSqlBuilder[T]) trackSensitive(column string, value reflect.Value) {
+	if key, ok := sensitiveKeyOf(value); ok && this.dao.sensitiveColumns[column] {
+		if this.sensitiveValues == nil {
+			this.sensitiveValues = make(map[sensitiveKey]string)
+		}
+		this.sensitiveValues[key] = column
+	}
+}
+
 func (this *DaoSqlBuilder[T]) toMap(s []string) map[string]struct{} {
 	m := make(map[string]struct{}, len(s))
 	if len(s) > 0 {
@@ -539,8 +755,12 @@ func newDaoSqlBuilder[T any](d *Dao[T], entities []*T) *DaoSqlBuilder[T] {
 
 type daoBuilder[T any] struct {
 	db                *sql.DB
+	resolver          DBResolver
 	allowInvalidField bool
 	columnMapper      *NameMapper
+	timeout           time.Duration
+	table             string
+	cfg               *CfgOverride
 }
 
 func (b *daoBuilder[T]) DB(db *sql.DB) *daoBuilder[T] {
@@ -548,6 +768,11 @@ func (b *daoBuilder[T]) DB(db *sql.DB) *daoBuilder[T] {
 	return b
 }
 
+func (b *daoBuilder[T]) Resolver(resolver DBResolver) *daoBuilder[T] {
+	b.resolver = resolver
+	return b
+}
+
 func (b *daoBuilder[T]) AllowInvalidField(allowInvalidField bool) *daoBuilder[T] {
 	b.allowInvalidField = allowInvalidField
 	return b
@@ -558,12 +783,33 @@ func (b *daoBuilder[T]) ColumnMapper(columnMapper *NameMapper) *daoBuilder[T] {
 	return b
 }
 
+// Timeout sets the default timeout of the statements of the dao.
+func (b *daoBuilder[T]) Timeout(timeout time.Duration) *daoBuilder[T] {
+	b.timeout = timeout
+	return b
+}
+
+// Table sets the table of the dao, the cached results of it are invalidated after the dao executes a statement.
+func (b *daoBuilder[T]) Table(table string) *daoBuilder[T] {
+	b.table = table
+	return b
+}
+
+// Config sets the config of the dao, the set fields override the global config, the others follow the changes of the
+// global config, see CfgOverride.
+func (b *daoBuilder[T]) Config(cfg CfgOverride) *daoBuilder[T] {
+	b.cfg = &cfg
+	return b
+}
+
 func (b *daoBuilder[T]) Build() *Dao[T] {
 	dao := &Dao[T]{
-		baseDao:                newBaseDao(b.db),
+		baseDao:                newBaseDao(b.db, b.resolver, b.timeout, b.table, b.cfg),
 		columnToFieldIndex:     make(map[string]int),
 		columnToFieldConvertor: make(map[string]fieldConvertor),
 		fieldNameToColumn:      make(map[string]string),
+		sensitiveColumns:       make(map[string]bool),
+		jsonColumns:            make(map[string]bool),
 	}
 	err := dao.registerEntity(b)
 	must(err)
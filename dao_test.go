@@ -17,6 +17,7 @@
 package gdao_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"reflect"
@@ -169,6 +170,14 @@ func TestNewDao(t *testing.T) {
 		r.Contains(export.AutoIncrementColumns, "id")
 		r.Equal(int64(1), export.AutoIncrementStep)
 		r.NotNil(export.AutoIncrementConvertor)
+		column, ok := dao.ColumnOf("CreateAt")
+		r.True(ok)
+		r.Equal("create_at", column)
+		column, ok = dao.ColumnOf("create_at")
+		r.True(ok)
+		r.Equal("create_at", column)
+		_, ok = dao.ColumnOf("unknown")
+		r.False(ok)
 	}
 	{
 		dao, _ := mockAccountDao(r)
@@ -775,3 +784,168 @@ func TestBuilder_EachEntity(t *testing.T) {
 		})
 	}).Do()
 }
+
+func TestDao_Timeout(t *testing.T) {
+	r := require.New(t)
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+	log := &MockLogger{}
+	gdao.Config(gdao.Cfg{DefaultDB: db, Logger: log, LogLevel: gdao.LogLevel_.INFO})
+	{
+		dao := gdao.DaoBuilder[User]().Build()
+		mock.ExpectPrepare("SELECT").ExpectQuery().WillDelayFor(time.Second).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		_, _, err := dao.Query().Timeout(10 * time.Millisecond).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("SELECT id FROM user")
+		}).Do()
+		r.ErrorIs(err, gdao.ErrTimeout)
+		var ge *gdao.Error
+		r.True(errors.As(err, &ge))
+		r.Contains(log.msg, "TIMEOUT, error: ")
+	}
+	{
+		dao := gdao.DaoBuilder[User]().Timeout(10 * time.Millisecond).Build()
+		mock.ExpectPrepare("UPDATE").ExpectExec().WillDelayFor(time.Second).WillReturnResult(sqlmock.NewResult(0, 1))
+		_, err := dao.Exec().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("UPDATE user SET status=1")
+		}).Do()
+		r.ErrorIs(err, gdao.ErrTimeout)
+
+		mock.ExpectPrepare("UPDATE").ExpectExec().WillDelayFor(20 * time.Millisecond).WillReturnResult(sqlmock.NewResult(0, 1))
+		affected, err := dao.Exec().Timeout(time.Second).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("UPDATE user SET status=1")
+		}).Do()
+		r.NoError(err)
+		r.Equal(int64(1), affected)
+	}
+	{
+		gdao.Config(gdao.Cfg{DefaultDB: db, Logger: log, LogLevel: gdao.LogLevel_.INFO, Timeout: 10 * time.Millisecond})
+		countDao := gdao.CountDaoBuilder().Build()
+		mock.ExpectPrepare("SELECT").ExpectQuery().WillDelayFor(time.Second).WillReturnRows(sqlmock.NewRows([]string{"count"}))
+		_, err := countDao.Count().BuildSql(func(b *gdao.CountBuilder) {
+			b.Write("SELECT COUNT(*) FROM user")
+		}).Do()
+		r.ErrorIs(err, gdao.ErrTimeout)
+
+		mock.ExpectPrepare("SELECT").ExpectQuery().WillDelayFor(20 * time.Millisecond).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		count, err := gdao.CountDaoBuilder().Timeout(time.Second).Build().Count().BuildSql(func(b *gdao.CountBuilder) {
+			b.Write("SELECT COUNT(*) FROM user")
+		}).Do()
+		r.NoError(err)
+		r.Equal(2, count.Int())
+	}
+	{
+		mock.ExpectPrepare("SELECT").ExpectQuery().WillReturnError(errors.New("bad connection"))
+		_, err := gdao.CountDaoBuilder().Build().Count().BuildSql(func(b *gdao.CountBuilder) {
+			b.Write("SELECT COUNT(*) FROM user")
+		}).Do()
+		r.False(errors.Is(err, gdao.ErrTimeout))
+		r.NotContains(log.msg, "TIMEOUT")
+	}
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func TestDao_ToSql(t *testing.T) {
+	r := require.New(t)
+	dao, mock := mockUserDao(r)
+	log := &MockLogger{}
+	{
+		sql, args, err := dao.Query().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("SELECT * FROM user WHERE name=? AND create_at>?", gdao.P("lucy"), time.Time{})
+		}).ToSql()
+		r.NoError(err)
+		r.Equal("SELECT * FROM user WHERE name=? AND create_at>?", sql)
+		r.Equal([]any{gdao.P("lucy"), time.Time{}}, args)
+
+		sql, args, err = dao.Exec().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("DELETE FROM user WHERE id=?", 1)
+		}).ToSql()
+		r.NoError(err)
+		r.Equal("DELETE FROM user WHERE id=?", sql)
+		r.Equal([]any{1}, args)
+
+		sql, args, err = gdao.CountDaoBuilder().Build().Count().BuildSql(func(b *gdao.CountBuilder) {
+			b.Write("SELECT COUNT(*) FROM user")
+		}).ToSql()
+		r.NoError(err)
+		r.Equal("SELECT COUNT(*) FROM user", sql)
+		r.Empty(args)
+
+		_, _, err = dao.Exec().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.SetError(errors.New("invalid"))
+		}).ToSql()
+		r.EqualError(err, "invalid")
+
+		sql, args, err = dao.Query().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("SELECT * FROM user WHERE id=?", 1)
+			b.SetOk(false)
+		}).ToSql()
+		r.NoError(err)
+		r.Empty(sql)
+		r.Nil(args)
+		sql, args, err = dao.Exec().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("DELETE FROM user WHERE id=?", 1)
+			b.SetOk(false)
+		}).ToSql()
+		r.NoError(err)
+		r.Empty(sql)
+		r.Nil(args)
+		sql, args, err = gdao.CountDaoBuilder().Build().Count().BuildSql(func(b *gdao.CountBuilder) {
+			b.Write("SELECT COUNT(*) FROM user WHERE id=?", 1)
+			b.SetOk(false)
+		}).ToSql()
+		r.NoError(err)
+		r.Empty(sql)
+		r.Nil(args)
+	}
+	{
+		ctx, capture := gdao.CaptureSql(nil)
+		_, _, err := dao.Query().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("SELECT * FROM user WHERE id=?", 1)
+		}).Do()
+		r.NoError(err)
+		_, err = dao.Exec().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("DELETE FROM user")
+		}).Do()
+		r.NoError(err)
+		sql, args, err := capture.Result(nil)
+		r.NoError(err)
+		r.Equal("SELECT * FROM user WHERE id=?", sql)
+		r.Equal([]any{1}, args)
+
+		ctx, capture = gdao.CaptureSql(context.Background())
+		count, err := gdao.CountDaoBuilder().Build().Count().Ctx(ctx).BuildSql(func(b *gdao.CountBuilder) {
+			b.Write("SELECT COUNT(*) FROM user")
+		}).Do()
+		r.NoError(err)
+		r.Nil(count)
+		sql, _, _ = capture.Result(nil)
+		r.Equal("SELECT COUNT(*) FROM user", sql)
+		_, _, err = capture.Result(errors.New("invalid"))
+		r.EqualError(err, "invalid")
+	}
+	{
+		gdao.Config(gdao.Cfg{DefaultDB: dao.DB(), Logger: log, LogLevel: gdao.LogLevel_.INFO, DryRun: true})
+		affected, err := dao.Exec().Desc("clean").BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("DELETE FROM user")
+		}).Do()
+		r.NoError(err)
+		r.Zero(affected)
+		r.Equal("DRY RUN, Desc: %s, SQL: %s;", log.msg)
+
+		u := &User{Name: gdao.P("lucy")}
+		_, _, err = dao.Query().Entities(u).RowAs(gdao.RowAs_.RETURNING).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("INSERT INTO user(name) VALUES(?) RETURNING id", u.Name)
+		}).Do()
+		r.NoError(err)
+		r.Nil(u.Id)
+		r.Equal("DRY RUN, SQL: %s; args: %v", log.msg)
+
+		mock.ExpectPrepare("SELECT").ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		_, list, err := dao.Query().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("SELECT id FROM user")
+		}).Do()
+		r.NoError(err)
+		r.Len(list, 1)
+	}
+	r.NoError(mock.ExpectationsWereMet())
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number, its value is unscaled * 10^-scale. It is bound as a string and scanned from the
+// text of the column, so that the values of DECIMAL/NUMERIC columns keep their precision. The zero value is 0.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+var decimalType = reflect.TypeFor[Decimal]()
+
+// NewDecimal returns the decimal unscaled * 10^-scale, e.g. NewDecimal(1234, 2) is 12.34.
+func NewDecimal(unscaled int64, scale int32) Decimal {
+	return Decimal{unscaled: big.NewInt(unscaled), scale: scale}.normalize()
+}
+
+// ParseDecimal parses the decimal string such as "-12.340", ".5" or "1.2e3", the scale is kept as written.
+func ParseDecimal(s string) (Decimal, error) {
+	str := s
+	var exp int64
+	if i := strings.IndexAny(str, "eE"); i != -1 {
+		e, err := strconv.ParseInt(str[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		str, exp = str[:i], e
+	}
+	var scale int64
+	if i := strings.IndexByte(str, '.'); i != -1 {
+		scale = int64(len(str) - i - 1)
+		str = str[:i] + str[i+1:]
+	}
+	digits := strings.TrimLeft(str, "+-")
+	if len(str)-len(digits) > 1 || digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	unscaled, _ := new(big.Int).SetString(str, 10)
+	scale -= exp
+	if scale < -1<<31 || scale > 1<<31-1 {
+		return Decimal{}, fmt.Errorf("decimal %q is out of range", s)
+	}
+	return Decimal{unscaled: unscaled, scale: int32(scale)}.normalize(), nil
+}
+
+// MustDecimal is like ParseDecimal but panics if the string is invalid.
+func MustDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	must(err)
+	return d
+}
+
+// normalize makes the scale non-negative, e.g. 12e2 becomes 1200.
+func (d Decimal) normalize() Decimal {
+	if d.scale < 0 {
+		d.unscaled = new(big.Int).Mul(d.int(), pow10(-d.scale))
+		d.scale = 0
+	}
+	return d
+}
+
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// Unscaled returns a copy of the unscaled integer.
+func (d Decimal) Unscaled() *big.Int {
+	return new(big.Int).Set(d.int())
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Rescale returns the decimal with the scale, the extra digits are rounded half away from zero.
+func (d Decimal) Rescale(scale int32) Decimal {
+	switch {
+	case scale > d.scale:
+		return Decimal{unscaled: new(big.Int).Mul(d.int(), pow10(scale-d.scale)), scale: scale}
+	case scale < d.scale:
+		q, r := new(big.Int).QuoRem(d.int(), pow10(d.scale-scale), new(big.Int))
+		if r.Abs(r).Lsh(r, 1).Cmp(pow10(d.scale-scale)) >= 0 {
+			q.Add(q, big.NewInt(int64(d.int().Sign())))
+		}
+		return Decimal{unscaled: q, scale: scale}.normalize()
+	default:
+		return d
+	}
+}
+
+// Cmp compares the decimals numerically, it returns -1, 0 or 1, e.g. 1.0 equals 1.00.
+func (d Decimal) Cmp(o Decimal) int {
+	scale := max(d.scale, o.scale)
+	return d.Rescale(scale).int().Cmp(o.Rescale(scale).int())
+}
+
+// Sign returns -1, 0 or 1 according to the sign of the decimal.
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// Float64 returns the nearest float64 of the decimal, it may lose precision.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns the decimal string with all digits of the scale, e.g. "-12.340".
+func (d Decimal) String() string {
+	s := d.int().String()
+	if d.scale <= 0 {
+		return s
+	}
+	sign := ""
+	if s[0] == '-' {
+		sign, s = "-", s[1:]
+	}
+	if n := int(d.scale) + 1 - len(s); n > 0 {
+		s = strings.Repeat("0", n) + s
+	}
+	return sign + s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+}
+
+// Value implements driver.Valuer, the decimal is bound as its string.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements sql.Scanner, it accepts the text, integer and float values of the column. NULL can't be scanned into
+// a Decimal, nullable columns must be scanned into a *Decimal, which database/sql sets to nil on NULL.
+func (d *Decimal) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case int64:
+		*d = NewDecimal(v, 0)
+		return nil
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return errors.New("cannot scan NULL into gdao.Decimal")
+	default:
+		return fmt.Errorf("cannot scan %T into gdao.Decimal", src)
+	}
+	v, err := ParseDecimal(strings.TrimSpace(s))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, the decimal is encoded as a JSON string to keep the precision.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	v, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jishaocong0910/gdao"
+	"github.com/stretchr/testify/require"
+)
+
+type Order struct {
+	Id     *int32        `gdao:"column=id;auto"`
+	Amount *gdao.Decimal `gdao:"column=amount"`
+}
+
+func TestDecimal(t *testing.T) {
+	r := require.New(t)
+	for s, want := range map[string]string{
+		"0": "0", "-12.340": "-12.340", "+.5": "0.5", "-0.001": "-0.001", "1.2e3": "1200", "1.25E-1": "0.125",
+		"123456789012345678901234567890.123456789": "123456789012345678901234567890.123456789",
+	} {
+		d, err := gdao.ParseDecimal(s)
+		r.NoError(err, s)
+		r.Equal(want, d.String(), s)
+	}
+	for _, s := range []string{"", "-", ".", "1.2.3", "1e", "--1", "1a", "e3"} {
+		_, err := gdao.ParseDecimal(s)
+		r.Error(err, s)
+	}
+	r.Panics(func() { gdao.MustDecimal("x") })
+
+	d := gdao.NewDecimal(-12345, 3)
+	r.Equal("-12.345", d.String())
+	r.Equal(int32(3), d.Scale())
+	r.Equal(big.NewInt(-12345), d.Unscaled())
+	r.Equal(-1, d.Sign())
+	r.Equal(-12.345, d.Float64())
+	r.Equal("-12.35", d.Rescale(2).String())
+	r.Equal("-12.3450", d.Rescale(4).String())
+	r.Equal("-10", d.Rescale(-1).String())
+	r.Equal("12.35", gdao.MustDecimal("12.345").Rescale(2).String())
+	r.Equal("12.34", gdao.MustDecimal("12.344").Rescale(2).String())
+	r.Equal("1200", gdao.NewDecimal(12, -2).String())
+	r.Equal("0", gdao.Decimal{}.String())
+	r.Equal(0, gdao.MustDecimal("1.0").Cmp(gdao.MustDecimal("1.00")))
+	r.Equal(1, gdao.MustDecimal("0.1").Cmp(gdao.MustDecimal("0.09")))
+	r.Equal(-1, gdao.Decimal{}.Cmp(gdao.MustDecimal("0.01")))
+
+	v, err := gdao.MustDecimal("0.10").Value()
+	r.NoError(err)
+	r.Equal("0.10", v)
+	var s gdao.Decimal
+	r.NoError(s.Scan([]byte("99999999999999999999.99")))
+	r.Equal("99999999999999999999.99", s.String())
+	r.NoError(s.Scan(" 1.5 "))
+	r.Equal("1.5", s.String())
+	r.NoError(s.Scan(int64(7)))
+	r.Equal("7", s.String())
+	r.NoError(s.Scan(0.25))
+	r.Equal("0.25", s.String())
+	r.EqualError(s.Scan(nil), "cannot scan NULL into gdao.Decimal")
+	r.EqualError(s.Scan(true), "cannot scan bool into gdao.Decimal")
+	r.Error(s.Scan("abc"))
+
+	data, err := json.Marshal(Order{Amount: gdao.P(gdao.MustDecimal("1.10"))})
+	r.NoError(err)
+	r.JSONEq(`{"Id":null,"Amount":"1.10"}`, string(data))
+	var o Order
+	r.NoError(json.Unmarshal(data, &o))
+	r.Equal("1.10", o.Amount.String())
+	r.Error(json.Unmarshal([]byte(`{"Amount":"x"}`), &o))
+}
+
+func TestDao_Decimal(t *testing.T) {
+	r := require.New(t)
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+	gdao.Config(gdao.Cfg{DefaultDB: db})
+	dao := gdao.DaoBuilder[Order]().Build()
+
+	mock.ExpectPrepare(`INSERT INTO order\(amount\) VALUES\(\?\)`).ExpectExec().
+		WithArgs("0.30").WillReturnResult(sqlmock.NewResult(1, 1))
+	_, err = dao.Exec().Entities(&Order{Amount: gdao.P(gdao.MustDecimal("0.30"))}).BuildSql(func(b *gdao.DaoSqlBuilder[Order]) {
+		b.Write("INSERT INTO order(amount) VALUES(?)", b.ColumnValue(b.Entity(), "amount"))
+	}).Do()
+	r.NoError(err)
+
+	mock.ExpectPrepare(`SELECT id, amount FROM order`).ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"id", "amount"}).
+			AddRow(1, []byte("12345678901234567890.12")).
+			AddRow(2, nil))
+	_, list, err := dao.Query().BuildSql(func(b *gdao.DaoSqlBuilder[Order]) {
+		b.Write("SELECT id, amount FROM order")
+	}).Do()
+	r.NoError(err)
+	r.Len(list, 2)
+	r.Equal("12345678901234567890.12", list[0].Amount.String())
+	r.Nil(list[1].Amount)
+
+	mock.ExpectQuery(`SELECT amount FROM order`).WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(nil))
+	amount := gdao.P(gdao.MustDecimal("1"))
+	r.NoError(db.QueryRow("SELECT amount FROM order").Scan(&amount))
+	r.Nil(amount)
+	mock.ExpectQuery(`SELECT amount FROM order`).WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(nil))
+	var value gdao.Decimal
+	r.ErrorContains(db.QueryRow("SELECT amount FROM order").Scan(&value), "cannot scan NULL into gdao.Decimal")
+	r.NoError(mock.ExpectationsWereMet())
+
+	type Invalid struct {
+		Amount gdao.Decimal `gdao:"column=amount"`
+	}
+	r.PanicsWithError(`field "Amount" of "gdao_test.Invalid" is not supported type`, func() {
+		gdao.DaoBuilder[Invalid]().Build()
+	})
+
+	r.Equal(`UPDATE order SET amount = 1.50 WHERE amount > 0`,
+		gdao.Dialect_.MYSQL.InlineSql(`UPDATE order SET amount = ? WHERE amount > ?`, []any{gdao.P(gdao.MustDecimal("1.50")), gdao.Decimal{}}))
+
+	c, err := gdao.CompareValues(gdao.P(gdao.MustDecimal("1.0")), gdao.MustDecimal("1.00"))
+	r.NoError(err)
+	r.Equal(0, c)
+	c, err = gdao.CompareValues(gdao.MustDecimal("0.3"), 0.25)
+	r.NoError(err)
+	r.Equal(1, c)
+	c, err = gdao.CompareValues(int32(2), gdao.P(gdao.MustDecimal("2.01")))
+	r.NoError(err)
+	r.Equal(-1, c)
+	_, err = gdao.CompareValues(gdao.MustDecimal("1"), true)
+	r.Error(err)
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// savepointSyntax is the prefixes of the savepoint statements, release is empty if the database does not support.
+type savepointSyntax struct {
+	save       string
+	rollbackTo string
+	release    string
+}
+
+// driverError locates the error code in an error type of a driver by reflection, so that the drivers need not be
+// imported. extCodeField is the field of the more specific code, it is optional.
+type driverError struct {
+	pkg          string
+	name         string
+	codeField    string
+	extCodeField string
+}
+
+var standardSavepoint = savepointSyntax{save: "SAVEPOINT ", rollbackTo: "ROLLBACK TO SAVEPOINT ", release: "RELEASE SAVEPOINT "}
+
+// dialectDriver is a driver.Driver reporting the dialect of the database it stands for, e.g. the driver of
+// gdaotest.Replay.
+type dialectDriver interface {
+	Dialect() Dialect
+}
+
+// DialectOf returns the dialect of db by the package of its driver, or by the driver reporting its dialect.
+func DialectOf(db *sql.DB) Dialect {
+	if db == nil {
+		return Dialect_.Undefined()
+	}
+	if d, ok := db.Driver().(dialectDriver); ok {
+		return d.Dialect()
+	}
+	t := reflect.TypeOf(db.Driver())
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for _, d := range Dialect_.Elems() {
+		for _, pkg := range d.driverPkgs {
+			if strings.HasPrefix(t.PkgPath(), pkg) {
+				return d
+			}
+		}
+	}
+	return Dialect_.Undefined()
+}
+
+func (d Dialect) savepointSyntax() savepointSyntax {
+	if d.IsUndefined() {
+		return standardSavepoint
+	}
+	return d.savepoint
+}
+
+// ErrorCode returns the error code of the first driver error of the dialect in the error chain.
+func (d Dialect) ErrorCode(err error) (code string, ok bool) {
+	code, _, ok = d.errorCodes(err)
+	return
+}
+
+func (d Dialect) errorCodes(err error) (code, extCode string, ok bool) {
+	if d.IsUndefined() {
+		return "", "", false
+	}
+	for ; err != nil; err = errors.Unwrap(err) {
+		v := reflect.ValueOf(err)
+		for v.Kind() == reflect.Pointer && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+		for _, de := range d.driverErrors {
+			if v.Type().PkgPath() == de.pkg && v.Type().Name() == de.name {
+				if de.extCodeField != "" {
+					extCode = codeString(v.FieldByName(de.extCodeField))
+				}
+				return codeString(v.FieldByName(de.codeField)), extCode, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// codeString formats the code by its kind, because the code types of some drivers implement fmt.Stringer.
+func codeString(v reflect.Value) string {
+	switch {
+	case v.CanInt():
+		return strconv.FormatInt(v.Int(), 10)
+	case v.CanUint():
+		return strconv.FormatUint(v.Uint(), 10)
+	case v.Kind() == reflect.String:
+		return v.String()
+	default: // coverage-ignore
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// Retryable reports whether err is a deadlock or serialization failure of the dialect.
+func (d Dialect) Retryable(err error) bool {
+	code, ok := d.ErrorCode(err)
+	return ok && slices.Contains(d.retryCodes, code)
+}
+
+// IsRetryable reports whether err is retryable in any of the dialects.
+func IsRetryable(err error) bool {
+	for _, d := range Dialect_.Elems() {
+		if d.Retryable(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// TranslateError wraps the driver error of the dialect with the sentinel error such as ErrDuplicateKey, so that it
+// can be checked by errors.Is, the message of err is kept. err is returned as is if it is not recognized.
+func (d Dialect) TranslateError(err error) error {
+	if te := d.translate(err); te != nil {
+		return te
+	}
+	return err
+}
+
+func (d Dialect) translate(err error) *translatedError {
+	var te *translatedError
+	if err == nil || errors.As(err, &te) {
+		return nil
+	}
+	code, extCode, ok := d.errorCodes(err)
+	if !ok {
+		return nil
+	}
+	sentinel, ok := d.errs[extCode]
+	if !ok {
+		sentinel, ok = d.errs[code]
+	}
+	if !ok {
+		return nil
+	}
+	return &translatedError{sentinel: sentinel, err: err}
+}
+
+// TranslateError translates err by the dialects of all supported drivers.
+func TranslateError(err error) error {
+	for _, d := range Dialect_.Elems() {
+		if te := d.translate(err); te != nil {
+			return te
+		}
+	}
+	return err
+}
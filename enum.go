@@ -59,6 +59,19 @@ type _RowAs struct {
 
 var RowAs_ = e.NewEnum[RowAs](_RowAs{})
 
+type Op struct {
+	*e.EnumElem__
+}
+
+type _Op struct {
+	*e.Enum__[Op]
+	QUERY,
+	EXEC,
+	COUNT Op
+}
+
+var Op_ = e.NewEnum[Op](_Op{})
+
 type lastInsertIdConvertor struct {
 	*e.EnumElem__
 	convert func(id int64) reflect.Value
@@ -86,3 +99,107 @@ var lastInsertIdConvertor_ = e.NewEnum[lastInsertIdConvertor](_lastInsertIdConve
 	float64: lastInsertIdConvertor{convert: func(id int64) reflect.Value { f := float64(id); return reflect.ValueOf(&f) }},
 	string:  lastInsertIdConvertor{convert: func(id int64) reflect.Value { s := strconv.FormatInt(id, 10); return reflect.ValueOf(&s) }},
 })
+
+type Propagation struct {
+	*e.EnumElem__
+}
+
+type _Propagation struct {
+	*e.Enum__[Propagation]
+	REQUIRED,
+	REQUIRES_NEW,
+	NESTED,
+	MANDATORY,
+	NEVER,
+	SUPPORTS Propagation
+}
+
+var Propagation_ = e.NewEnum[Propagation](_Propagation{})
+
+type Dialect struct {
+	*e.EnumElem__
+	driverPkgs   []string
+	driverErrors []driverError
+	savepoint    savepointSyntax
+	retryCodes   []string
+	errs         map[string]error
+	explain      *explainSyntax
+	literal      *literalSyntax
+}
+
+type _Dialect struct {
+	*e.Enum__[Dialect]
+	MYSQL,
+	POSTGRES,
+	ORACLE,
+	SQLSERVER,
+	SQLITE Dialect
+}
+
+var Dialect_ = e.NewEnum[Dialect](_Dialect{
+	MYSQL: Dialect{
+		driverPkgs:   []string{"github.com/go-sql-driver/mysql"},
+		driverErrors: []driverError{{pkg: "github.com/go-sql-driver/mysql", name: "MySQLError", codeField: "Number"}},
+		savepoint:    standardSavepoint,
+		retryCodes:   []string{"1213", "1205"},
+		errs: map[string]error{
+			"1062": ErrDuplicateKey, "1586": ErrDuplicateKey,
+			"1216": ErrForeignKey, "1217": ErrForeignKey, "1451": ErrForeignKey, "1452": ErrForeignKey,
+			"1048": ErrNotNull, "3819": ErrCheck, "1213": ErrDeadlock, "1205": ErrLockTimeout,
+		},
+		explain: &explainSyntax{prefix: "EXPLAIN FORMAT=JSON ", analyze: analyzeMysqlPlan},
+		literal: &mysqlLiteral,
+	},
+	POSTGRES: Dialect{
+		driverPkgs: []string{"github.com/lib/pq", "github.com/jackc/pgx"},
+		driverErrors: []driverError{
+			{pkg: "github.com/lib/pq", name: "Error", codeField: "Code"},
+			{pkg: "github.com/jackc/pgx/v5/pgconn", name: "PgError", codeField: "Code"},
+		},
+		savepoint:  standardSavepoint,
+		retryCodes: []string{"40001", "40P01"},
+		errs: map[string]error{
+			"23505": ErrDuplicateKey, "23503": ErrForeignKey, "23502": ErrNotNull, "23514": ErrCheck,
+			"40P01": ErrDeadlock, "55P03": ErrLockTimeout, "40001": ErrSerialization,
+		},
+		explain: &explainSyntax{prefix: "EXPLAIN (FORMAT JSON) ", analyze: analyzePostgresPlan},
+		literal: &postgresLiteral,
+	},
+	ORACLE: Dialect{
+		driverPkgs:   []string{"github.com/sijms/go-ora", "github.com/godror/godror"},
+		driverErrors: []driverError{{pkg: "github.com/sijms/go-ora/v2/network", name: "OracleError", codeField: "ErrCode"}},
+		savepoint:    savepointSyntax{save: "SAVEPOINT ", rollbackTo: "ROLLBACK TO SAVEPOINT "},
+		retryCodes:   []string{"60", "8177"},
+		errs: map[string]error{
+			"1": ErrDuplicateKey, "2291": ErrForeignKey, "2292": ErrForeignKey, "1400": ErrNotNull, "1407": ErrNotNull,
+			"2290": ErrCheck, "60": ErrDeadlock, "54": ErrLockTimeout, "30006": ErrLockTimeout, "8177": ErrSerialization,
+		},
+		literal: &oracleLiteral,
+	},
+	SQLSERVER: Dialect{
+		driverPkgs: []string{"github.com/microsoft/go-mssqldb", "github.com/denisenkom/go-mssqldb"},
+		driverErrors: []driverError{
+			{pkg: "github.com/microsoft/go-mssqldb", name: "Error", codeField: "Number"},
+			{pkg: "github.com/denisenkom/go-mssqldb", name: "Error", codeField: "Number"},
+		},
+		savepoint:  savepointSyntax{save: "SAVE TRANSACTION ", rollbackTo: "ROLLBACK TRANSACTION "},
+		retryCodes: []string{"1205"},
+		errs: map[string]error{
+			"2627": ErrDuplicateKey, "2601": ErrDuplicateKey, "547": ErrForeignKey, "515": ErrNotNull,
+			"1205": ErrDeadlock, "1222": ErrLockTimeout, "3960": ErrSerialization,
+		},
+		literal: &sqlserverLiteral,
+	},
+	SQLITE: Dialect{
+		driverPkgs:   []string{"github.com/mattn/go-sqlite3", "modernc.org/sqlite"},
+		driverErrors: []driverError{{pkg: "github.com/mattn/go-sqlite3", name: "Error", codeField: "Code", extCodeField: "ExtendedCode"}},
+		savepoint:    standardSavepoint,
+		retryCodes:   []string{"5", "6"},
+		errs: map[string]error{
+			"2067": ErrDuplicateKey, "1555": ErrDuplicateKey, "787": ErrForeignKey, "1299": ErrNotNull, "275": ErrCheck,
+			"5": ErrLockTimeout, "6": ErrLockTimeout,
+		},
+		explain: &explainSyntax{prefix: "EXPLAIN QUERY PLAN ", analyze: analyzeSqlitePlan},
+		literal: &sqliteLiteral,
+	},
+})
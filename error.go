@@ -0,0 +1,84 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNoTx       = errors.New("no existing transaction for propagation MANDATORY")
+	ErrExistingTx = errors.New("existing transaction found for propagation NEVER")
+	ErrNoShardKey = errors.New("no shard key")
+)
+
+var (
+	ErrDuplicateKey  = errors.New("duplicate key")
+	ErrForeignKey    = errors.New("foreign key violation")
+	ErrNotNull       = errors.New("not null violation")
+	ErrCheck         = errors.New("check constraint violation")
+	ErrDeadlock      = errors.New("deadlock")
+	ErrLockTimeout   = errors.New("lock timeout")
+	ErrSerialization = errors.New("serialization failure")
+	ErrTimeout       = errors.New("statement timeout")
+)
+
+type UnknownColumnError struct {
+	Column string
+}
+
+func (e *UnknownColumnError) Error() string {
+	return `unknown column "` + e.Column + `"`
+}
+
+// translatedError keeps the message of the driver error, and it matches the sentinel error by errors.Is.
+type translatedError struct {
+	sentinel error
+	err      error
+}
+
+func (e *translatedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *translatedError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+func (e *translatedError) Unwrap() error {
+	return e.err
+}
+
+// Error is the failure of a statement, it carries the context of the statement and wraps the cause.
+type Error struct {
+	Op      Op
+	Desc    string
+	Sql     string
+	Args    []any
+	Elapsed time.Duration
+	InTx    bool
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
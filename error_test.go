@@ -0,0 +1,171 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jishaocong0910/gdao"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/sijms/go-ora/v2/network"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateError(t *testing.T) {
+	r := require.New(t)
+	cases := []struct {
+		err      error
+		sentinel error
+	}{
+		{&mysql.MySQLError{Number: 1062}, gdao.ErrDuplicateKey},
+		{&mysql.MySQLError{Number: 1452}, gdao.ErrForeignKey},
+		{&mysql.MySQLError{Number: 1048}, gdao.ErrNotNull},
+		{&mysql.MySQLError{Number: 1213}, gdao.ErrDeadlock},
+		{&mysql.MySQLError{Number: 1205}, gdao.ErrLockTimeout},
+		{&pq.Error{Code: "23505"}, gdao.ErrDuplicateKey},
+		{&pq.Error{Code: "23503"}, gdao.ErrForeignKey},
+		{&pq.Error{Code: "23514"}, gdao.ErrCheck},
+		{&pq.Error{Code: "40001"}, gdao.ErrSerialization},
+		{&network.OracleError{ErrCode: 1}, gdao.ErrDuplicateKey},
+		{&network.OracleError{ErrCode: 1400}, gdao.ErrNotNull},
+		{&network.OracleError{ErrCode: 30006}, gdao.ErrLockTimeout},
+		{mssql.Error{Number: 2627}, gdao.ErrDuplicateKey},
+		{mssql.Error{Number: 547}, gdao.ErrForeignKey},
+		{mssql.Error{Number: 1205}, gdao.ErrDeadlock},
+		{sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}, gdao.ErrDuplicateKey},
+		{sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintNotNull}, gdao.ErrNotNull},
+		{sqlite3.Error{Code: sqlite3.ErrBusy}, gdao.ErrLockTimeout},
+	}
+	for _, c := range cases {
+		err := gdao.TranslateError(fmt.Errorf("wrap: %w", c.err))
+		r.ErrorIs(err, c.sentinel, c.err.Error())
+		r.Equal("wrap: "+c.err.Error(), err.Error())
+		r.Same(err, gdao.TranslateError(err))
+	}
+	{
+		err := gdao.TranslateError(&mysql.MySQLError{Number: 1213})
+		var me *mysql.MySQLError
+		r.True(errors.As(err, &me))
+		r.Equal(uint16(1213), me.Number)
+		r.True(gdao.IsRetryable(err))
+		r.False(errors.Is(err, gdao.ErrDuplicateKey))
+	}
+	{
+		err := &mysql.MySQLError{Number: 1064}
+		r.Same(err, gdao.TranslateError(err))
+		r.Same(err, gdao.Dialect_.POSTGRES.TranslateError(err))
+		r.Nil(gdao.TranslateError(nil))
+		r.Nil(gdao.Dialect_.Undefined().TranslateError(nil))
+	}
+}
+
+func TestDao_TranslateError(t *testing.T) {
+	r := require.New(t)
+	dao, mock := mockUserDao(r)
+	mock.ExpectPrepare("INSERT INTO user").ExpectExec().WillReturnError(&mysql.MySQLError{Number: 1062})
+	_, err := dao.Exec().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+		b.Write("INSERT INTO user(id) VALUES(1)")
+	}).Do()
+	r.ErrorIs(err, gdao.ErrDuplicateKey)
+
+	mock.ExpectPrepare("SELECT").ExpectQuery().WillReturnError(&pq.Error{Code: "55P03"})
+	_, _, err = dao.Query().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+		b.Write("SELECT * FROM user FOR UPDATE NOWAIT")
+	}).Do()
+	r.ErrorIs(err, gdao.ErrLockTimeout)
+
+	countDao := gdao.CountDaoBuilder().Build()
+	mock.ExpectPrepare("SELECT").ExpectQuery().WillReturnError(mssql.Error{Number: 1205})
+	_, err = countDao.Count().BuildSql(func(b *gdao.CountBuilder) {
+		b.Write("SELECT COUNT(*) FROM user")
+	}).Do()
+	r.ErrorIs(err, gdao.ErrDeadlock)
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func TestError(t *testing.T) {
+	r := require.New(t)
+	dao, mock := mockUserDao(r)
+	{
+		mock.ExpectPrepare("UPDATE user").ExpectExec().WithArgs("lucy", 1).WillReturnError(&mysql.MySQLError{Number: 1062})
+		_, err := dao.Exec().Desc("rename").BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("UPDATE user SET name=? WHERE id=?", "lucy", 1)
+		}).Do()
+		var ge *gdao.Error
+		r.True(errors.As(err, &ge))
+		r.Equal(gdao.Op_.EXEC, ge.Op)
+		r.Equal("rename", ge.Desc)
+		r.Equal("UPDATE user SET name=? WHERE id=?", ge.Sql)
+		r.Equal([]any{"lucy", 1}, ge.Args)
+		r.False(ge.InTx)
+		r.Positive(ge.Elapsed)
+		r.ErrorIs(err, gdao.ErrDuplicateKey)
+		r.Equal("Error 1062: ", err.Error())
+	}
+	{
+		mock.ExpectBegin()
+		mock.ExpectPrepare("SELECT").ExpectQuery().WillReturnError(errors.New("bad connection"))
+		mock.ExpectRollback()
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			_, _, err := dao.Query().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+				b.Write("SELECT * FROM user WHERE id=?", 1)
+			}).Do()
+			return err
+		})
+		var ge *gdao.Error
+		r.True(errors.As(err, &ge))
+		r.Equal(gdao.Op_.QUERY, ge.Op)
+		r.True(ge.InTx)
+		r.EqualError(ge.Unwrap(), "bad connection")
+	}
+	{
+		gdao.Config(gdao.Cfg{DefaultDB: dao.DB(), RedactErrorArgs: true})
+		mock.ExpectPrepare("SELECT").ExpectQuery().WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1).AddRow(2))
+		_, err := gdao.CountDaoBuilder().Build().Count().BuildSql(func(b *gdao.CountBuilder) {
+			b.Write("SELECT COUNT(*) FROM user WHERE status=?", 1)
+		}).Do()
+		var ge *gdao.Error
+		r.True(errors.As(err, &ge))
+		r.Equal(gdao.Op_.COUNT, ge.Op)
+		r.Equal([]any{"***"}, ge.Args)
+		r.EqualError(err, "returns more than one row")
+	}
+	{
+		log := &MockLogger{}
+		gdao.Config(gdao.Cfg{DefaultDB: dao.DB(), Logger: log, LogLevel: gdao.LogLevel_.DEBUG})
+		mock.ExpectPrepare("UPDATE user").ExpectExec().WithArgs("lucy", 1).WillReturnError(&mysql.MySQLError{Number: 1062})
+		_, err := dao.Exec().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("UPDATE user SET name=? WHERE id=?", "lucy", 1)
+		}).Do()
+		r.Error(err)
+		logged, ok := log.args[len(log.args)-1].(error)
+		r.True(ok)
+		var ge *gdao.Error
+		r.True(errors.As(logged, &ge))
+		r.Equal(gdao.Op_.EXEC, ge.Op)
+		r.ErrorIs(logged, gdao.ErrDuplicateKey)
+	}
+	r.NoError(mock.ExpectationsWereMet())
+}
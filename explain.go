@@ -0,0 +1,162 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// explainSyntax runs EXPLAIN in the dialect and finds the full table scans and filesorts in the plan.
+type explainSyntax struct {
+	prefix  string
+	analyze func(rows *sql.Rows) ([]string, error)
+}
+
+// explain runs EXPLAIN for the SELECT, UPDATE and DELETE statements which take longer than Cfg.ExplainThreshold, and
+// warns if the plan has a full table scan or filesort. It runs after the statement is finished, because the connection
+// of a transaction can not be used by two statements at the same time.
+func (d baseDao) explain(ctx context.Context, desc, _sql string, args []any, start time.Time) {
+	cfg := d.config()
+	if !cfg.Explain || time.Since(start) < cfg.ExplainThreshold {
+		return
+	}
+	kind, _, _ := strings.Cut(strings.TrimSpace(_sql), " ")
+	kind = strings.ToUpper(kind)
+	if kind != "SELECT" && kind != "UPDATE" && kind != "DELETE" {
+		return
+	}
+	syntax := DialectOf(d.dbOf(ctx)).explain
+	if syntax == nil {
+		return
+	}
+	rows, _, closeFunc, err := d.query(ctx, syntax.prefix+_sql, args, kind != "SELECT", 0)
+	if err != nil { // coverage-ignore
+		cfg.printWarn(ctx, err)
+		return
+	}
+	defer closeFunc()
+	findings, err := syntax.analyze(rows)
+	if err != nil { // coverage-ignore
+		cfg.printWarn(ctx, err)
+		return
+	}
+	cfg.printExplainWarn(ctx, desc, _sql, findings)
+}
+
+func scanPlanJSON(rows *sql.Rows) (plan any, err error) {
+	var data []byte
+	if rows.Next() {
+		if err = rows.Scan(&data); err != nil { // coverage-ignore
+			return nil, err
+		}
+	}
+	err = json.Unmarshal(data, &plan)
+	return
+}
+
+// walkPlan visits all objects of the JSON plan.
+func walkPlan(plan any, visit func(node map[string]any)) {
+	switch p := plan.(type) {
+	case map[string]any:
+		visit(p)
+		for _, k := range slices.Sorted(maps.Keys(p)) {
+			walkPlan(p[k], visit)
+		}
+	case []any:
+		for _, v := range p {
+			walkPlan(v, visit)
+		}
+	}
+}
+
+func analyzeMysqlPlan(rows *sql.Rows) ([]string, error) {
+	plan, err := scanPlanJSON(rows)
+	if err != nil { // coverage-ignore
+		return nil, err
+	}
+	return mysqlPlanFindings(plan), nil
+}
+
+func mysqlPlanFindings(plan any) []string {
+	var findings []string
+	walkPlan(plan, func(node map[string]any) {
+		if node["access_type"] == "ALL" {
+			findings = append(findings, "full table scan on "+fmtString(node["table_name"]))
+		}
+		if node["using_filesort"] == true {
+			findings = append(findings, "filesort")
+		}
+	})
+	return findings
+}
+
+func analyzePostgresPlan(rows *sql.Rows) ([]string, error) {
+	plan, err := scanPlanJSON(rows)
+	if err != nil { // coverage-ignore
+		return nil, err
+	}
+	return postgresPlanFindings(plan), nil
+}
+
+func postgresPlanFindings(plan any) []string {
+	var findings []string
+	walkPlan(plan, func(node map[string]any) {
+		switch node["Node Type"] {
+		case "Seq Scan":
+			findings = append(findings, "full table scan on "+fmtString(node["Relation Name"]))
+		case "Sort", "Incremental Sort":
+			findings = append(findings, "filesort")
+		}
+	})
+	return findings
+}
+
+func analyzeSqlitePlan(rows *sql.Rows) ([]string, error) {
+	var findings []string
+	for rows.Next() {
+		var id, parent, notUsed int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil { // coverage-ignore
+			return nil, err
+		}
+		findings = append(findings, sqlitePlanFindings(detail)...)
+	}
+	return findings, nil
+}
+
+func sqlitePlanFindings(detail string) []string {
+	switch {
+	case strings.HasPrefix(detail, "SCAN ") && !strings.Contains(detail, " INDEX "):
+		table := strings.TrimPrefix(strings.TrimPrefix(detail, "SCAN "), "TABLE ")
+		table, _, _ = strings.Cut(table, " ")
+		return []string{"full table scan on " + table}
+	case strings.HasPrefix(detail, "USE TEMP B-TREE FOR ") && strings.HasSuffix(detail, "ORDER BY"):
+		return []string{"filesort"}
+	}
+	return nil
+}
+
+func fmtString(v any) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jishaocong0910/gdao"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	r := require.New(t)
+	db, err := sql.Open("sqlite3", ":memory:")
+	r.NoError(err)
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec("CREATE TABLE user (id INTEGER PRIMARY KEY, name TEXT, status INTEGER)")
+	r.NoError(err)
+	_, err = db.Exec("CREATE INDEX idx_name ON user (name)")
+	r.NoError(err)
+	log := &MockLogger{}
+	gdao.Config(gdao.Cfg{DefaultDB: db, Logger: log, Explain: true})
+	dao := gdao.DaoBuilder[User]().Build()
+	{
+		_, _, err = dao.Query().Desc("list by status").BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("SELECT id FROM user WHERE status>? ORDER BY status", 1)
+		}).Do()
+		r.NoError(err)
+		r.Equal("EXPLAIN, Desc: %s, SQL: %s; %s", log.msg)
+		r.Equal([]any{"list by status", "SELECT id FROM user WHERE status>? ORDER BY status", "full table scan on user, filesort"}, log.args)
+	}
+	{
+		*log = MockLogger{}
+		_, _, err = dao.Query().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("SELECT id FROM user WHERE name=?", "lucy")
+		}).Do()
+		r.NoError(err)
+		r.Empty(log.msg)
+
+		_, err = dao.Exec().Desc("disable").BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("UPDATE user SET status=0 WHERE status=?", 1)
+		}).Do()
+		r.NoError(err)
+		r.Equal([]any{"disable", "UPDATE user SET status=0 WHERE status=?", "full table scan on user"}, log.args)
+
+		*log = MockLogger{}
+		_, err = dao.Exec().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("INSERT INTO user(name) VALUES(?)", "lucy")
+		}).Do()
+		r.NoError(err)
+		r.Empty(log.msg)
+
+		_, err = gdao.CountDaoBuilder().Build().Count().BuildSql(func(b *gdao.CountBuilder) {
+			b.Write("SELECT COUNT(*) FROM user WHERE status=?", 1)
+		}).Do()
+		r.NoError(err)
+		r.Equal([]any{"SELECT COUNT(*) FROM user WHERE status=?", "full table scan on user"}, log.args)
+	}
+	{
+		*log = MockLogger{}
+		gdao.Config(gdao.Cfg{DefaultDB: db, Logger: log, Explain: true, ExplainThreshold: time.Hour})
+		_, _, err = dao.Query().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("SELECT id FROM user")
+		}).Do()
+		r.NoError(err)
+		r.Empty(log.msg)
+	}
+}
+
+func TestExplain_Plan(t *testing.T) {
+	r := require.New(t)
+	var plan any
+	r.NoError(json.Unmarshal([]byte(`{"query_block": {"select_id": 1, "ordering_operation": {"using_filesort": true,
+		"table": {"table_name": "user", "access_type": "ALL"}}}}`), &plan))
+	r.Equal([]string{"filesort", "full table scan on user"}, gdao.MysqlPlanFindings(plan))
+	r.NoError(json.Unmarshal([]byte(`{"query_block": {"table": {"table_name": "user", "access_type": "ref"}}}`), &plan))
+	r.Empty(gdao.MysqlPlanFindings(plan))
+
+	r.NoError(json.Unmarshal([]byte(`[{"Plan": {"Node Type": "Sort", "Plans": [{"Node Type": "Seq Scan",
+		"Relation Name": "user"}]}}]`), &plan))
+	r.Equal([]string{"filesort", "full table scan on user"}, gdao.PostgresPlanFindings(plan))
+	r.NoError(json.Unmarshal([]byte(`[{"Plan": {"Node Type": "Index Scan", "Relation Name": "user"}}]`), &plan))
+	r.Empty(gdao.PostgresPlanFindings(plan))
+
+	r.Equal([]string{"full table scan on user"}, gdao.SqlitePlanFindings("SCAN TABLE user"))
+	r.Empty(gdao.SqlitePlanFindings("SCAN user USING COVERING INDEX idx_name"))
+	r.Empty(gdao.SqlitePlanFindings("SEARCH user USING INDEX idx_name (name=?)"))
+}
@@ -17,7 +17,10 @@
 package gdao
 
 import (
+	"context"
+	"database/sql"
 	"reflect"
+	"time"
 )
 
 type DaoExport struct {
@@ -48,5 +51,30 @@ func ConvertLastInsertId(typeName string, id int64) any {
 	return lastInsertIdConvertor_.OfString(typeName).convert(id).Elem().Interface()
 }
 
-var PrintSql = printSql
+func PrintSql(ctx context.Context, db *sql.DB, logLevel LogLevel, desc string, sql string, args []any, affected, rowCounts int64, err error) {
+	globalCfg().printSql(ctx, db, logLevel, desc, sql, args, affected, rowCounts, err)
+}
+
 var PrintWarn = printWarn
+
+func ExportTxOptions(opts ...TxOption) *sql.TxOptions {
+	o := &txOption{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o.txOptions()
+}
+
+func CheckHealth(c *Cluster) {
+	c.checkHealth(time.Second)
+}
+
+var MysqlPlanFindings = mysqlPlanFindings
+
+var PostgresPlanFindings = postgresPlanFindings
+
+var SqlitePlanFindings = sqlitePlanFindings
+
+func UnmarshalJSON(codec JSONCodec, data []byte, field reflect.Value) error {
+	return unmarshalJSON(codec, data, field)
+}
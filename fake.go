@@ -0,0 +1,464 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeTable is an in-memory table of the entities, it backs the fake of the generated base daos. The rows are keyed
+// by the primary key columns tagged pk, or by the auto increment column if no column is tagged pk, and the auto
+// increment column of the inserted rows is generated if it is nil. The rows are copied in and out, so that the callers
+// can not modify them.
+type FakeTable[T any] struct {
+	dao    *Dao[T]
+	mu     sync.Mutex
+	rows   []*T
+	lastId int64
+}
+
+// Find returns the copies of the rows matched in the inserted order.
+func (f *FakeTable[T]) Find(match func(row *T) (bool, error)) ([]*T, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	list := make([]*T, 0)
+	for _, row := range f.rows {
+		ok, err := match(row)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			list = append(list, f.clone(row))
+		}
+	}
+	return list, nil
+}
+
+// Insert inserts the entities like an INSERT statement, the ignored columns are not set, and the auto increment column
+// is generated and set to the entities.
+func (f *FakeTable[T]) Insert(entities []*T, ignore ...string) error {
+	_, err := f.insert(entities, true, ignore, false, nil)
+	return err
+}
+
+// Upsert inserts the entities like Insert, except for the entities whose keys exist. They are skipped like INSERT
+// IGNORE if update is nil, otherwise update is called with a copy of the existing row like ON DUPLICATE KEY UPDATE.
+// The affected rows are counted like MySQL, 1 for an inserted row, 2 for an updated row and 0 for the others.
+func (f *FakeTable[T]) Upsert(entities []*T, update func(row *T) error, ignore ...string) (int64, error) {
+	return f.insert(entities, true, ignore, true, update)
+}
+
+// insert inserts the entities, the auto increment column is generated if generate is true or it is nil. If a key
+// exists, the entity is upserted if upsert is true, otherwise nothing is inserted and an error of ErrDuplicateKey is
+// returned.
+func (f *FakeTable[T]) insert(entities []*T, generate bool, ignore []string, upsert bool, update func(row *T) error) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	auto, hasAuto := f.autoIncrementColumn()
+	lastId := f.lastId
+	rows := slices.Clone(f.rows)
+	inserted := make(map[int]*T, len(entities))
+	var affected int64
+	for n, entity := range entities {
+		row := f.clone(entity)
+		for _, column := range ignore {
+			if err := f.Set(row, column, nil); err != nil {
+				return 0, err
+			}
+		}
+		if hasAuto {
+			if id := f.Value(row, auto); id == nil || generate {
+				lastId += f.dao.autoIncrementStep
+				reflect.ValueOf(row).Elem().Field(f.dao.columnToFieldIndex[auto]).Set(f.dao.autoIncrementConvert(lastId))
+			} else if i, ok := toInt64(id); ok && i > lastId {
+				lastId = i
+			}
+		}
+		j := f.indexOfKey(row, rows)
+		if j < 0 {
+			rows = append(rows, row)
+			inserted[n] = row
+			affected++
+			continue
+		}
+		if !upsert {
+			return 0, f.duplicateKeyError(row)
+		}
+		if update == nil {
+			continue
+		}
+		updated := f.clone(rows[j])
+		if err := update(updated); err != nil {
+			return 0, err
+		}
+		if err := f.checkKey(updated, rows[:j], rows[j+1:]); err != nil {
+			return 0, err
+		}
+		if !reflect.DeepEqual(updated, rows[j]) {
+			rows[j] = updated
+			affected += 2
+		}
+	}
+	if hasAuto {
+		for n, row := range inserted {
+			_ = f.Set(entities[n], auto, f.Value(row, auto))
+		}
+	}
+	f.rows = rows
+	f.lastId = lastId
+	return affected, nil
+}
+
+// Update sets the columns of the rows matched to the fields of entity, the nil fields are skipped unless all is true.
+// The columns of setNull are set to nil, and the ignored columns are not changed. The changes are made on the copies
+// of the rows, and nothing is changed if an error occurs.
+func (f *FakeTable[T]) Update(match func(row *T) (bool, error), entity *T, all bool, setNull []string, ignore ...string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ignored := make(map[string]bool, len(ignore)+len(setNull))
+	for _, column := range append(ignore, setNull...) {
+		ignored[column] = true
+	}
+	rows := slices.Clone(f.rows)
+	var updated []*T
+	for i, row := range f.rows {
+		ok, err := match(row)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			continue
+		}
+		row = f.clone(row)
+		for _, column := range f.dao.columns {
+			if ignored[column] {
+				continue
+			}
+			if value := f.Value(entity, column); value != nil || all {
+				if err = f.Set(row, column, value); err != nil { // coverage-ignore
+					return 0, err
+				}
+			}
+		}
+		for _, column := range setNull {
+			if err = f.Set(row, column, nil); err != nil {
+				return 0, err
+			}
+		}
+		rows[i] = row
+		updated = append(updated, row)
+	}
+	for _, row := range updated {
+		if err := f.checkKey(row, rows); err != nil {
+			return 0, err
+		}
+	}
+	f.rows = rows
+	return int64(len(updated)), nil
+}
+
+// Delete deletes the rows matched, nothing is deleted if an error occurs.
+func (f *FakeTable[T]) Delete(match func(row *T) (bool, error)) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rows := make([]*T, 0, len(f.rows))
+	for _, row := range f.rows {
+		ok, err := match(row)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			rows = append(rows, row)
+		}
+	}
+	affected := int64(len(f.rows) - len(rows))
+	f.rows = rows
+	return affected, nil
+}
+
+// Value returns the value of the column of row, the pointer is dereferenced, nil means NULL.
+func (f *FakeTable[T]) Value(row *T, column string) any {
+	return f.dao.ColumnValue(row, column)
+}
+
+// Set sets the column of row to a copy of value, value is converted to the type of the field if possible.
+func (f *FakeTable[T]) Set(row *T, column string, value any) error {
+	index, ok := f.dao.columnToFieldIndex[column]
+	if !ok {
+		return &UnknownColumnError{Column: column}
+	}
+	field := reflect.ValueOf(row).Elem().Field(index)
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Kind() == reflect.Pointer {
+		field.SetZero()
+		return nil
+	}
+	ft := field.Type()
+	if ft.Kind() == reflect.Pointer {
+		if !v.Type().ConvertibleTo(ft.Elem()) {
+			return fmt.Errorf("can not set %T to column %s of type %s", value, column, ft)
+		}
+		p := reflect.New(ft.Elem())
+		p.Elem().Set(v.Convert(ft.Elem()))
+		field.Set(p)
+		return nil
+	}
+	if !v.Type().ConvertibleTo(ft) {
+		return fmt.Errorf("can not set %T to column %s of type %s", value, column, ft)
+	}
+	v = v.Convert(ft)
+	if v.Kind() == reflect.Slice {
+		v = reflect.AppendSlice(reflect.MakeSlice(ft, 0, v.Len()), v)
+	}
+	field.Set(v)
+	return nil
+}
+
+// Project keeps the columns of row and sets the others to nil.
+func (f *FakeTable[T]) Project(row *T, columns ...string) {
+	kept := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		kept[column] = true
+	}
+	for _, column := range f.dao.columns {
+		if !kept[column] {
+			_ = f.Set(row, column, nil)
+		}
+	}
+}
+
+func (f *FakeTable[T]) autoIncrementColumn() (string, bool) {
+	if len(f.dao.autoIncrementColumns) == 0 {
+		return "", false
+	}
+	return f.dao.autoIncrementColumns[0], true
+}
+
+// keys returns the columns keying the rows, they are the primary key columns, or the auto increment column if no
+// column is tagged pk.
+func (f *FakeTable[T]) keys() []string {
+	if len(f.dao.primaryKeyColumns) > 0 {
+		return f.dao.primaryKeyColumns
+	}
+	if auto, ok := f.autoIncrementColumn(); ok {
+		return []string{auto}
+	}
+	return nil
+}
+
+// checkKey returns an error of ErrDuplicateKey if another row of the groups has the key of row.
+func (f *FakeTable[T]) checkKey(row *T, groups ...[]*T) error {
+	for _, rows := range groups {
+		if f.indexOfKey(row, rows) >= 0 {
+			return f.duplicateKeyError(row)
+		}
+	}
+	return nil
+}
+
+// indexOfKey returns the index of another row of rows having the key of row, -1 if not found. The keys having NULL
+// are never duplicate.
+func (f *FakeTable[T]) indexOfKey(row *T, rows []*T) int {
+	keys := f.keys()
+	if len(keys) == 0 {
+		return -1
+	}
+	values := make([]any, len(keys))
+	for i, key := range keys {
+		if values[i] = f.Value(row, key); values[i] == nil {
+			return -1
+		}
+	}
+	return slices.IndexFunc(rows, func(r *T) bool {
+		return r != row && slices.EqualFunc(keys, values, func(key string, value any) bool {
+			c, _ := CompareValues(f.Value(r, key), value)
+			return c == 0
+		})
+	})
+}
+
+func (f *FakeTable[T]) duplicateKeyError(row *T) error {
+	keys := f.keys()
+	if len(keys) == 1 {
+		return &translatedError{sentinel: ErrDuplicateKey, err: fmt.Errorf("duplicate key %v of column %s", f.Value(row, keys[0]), keys[0])}
+	}
+	values := make([]any, len(keys))
+	for i, key := range keys {
+		values[i] = f.Value(row, key)
+	}
+	return &translatedError{sentinel: ErrDuplicateKey, err: fmt.Errorf("duplicate key %v of columns %s", values, strings.Join(keys, ", "))}
+}
+
+// clone deep copies the fields of the columns of entity.
+func (f *FakeTable[T]) clone(entity *T) *T {
+	row := new(T)
+	src, dst := reflect.ValueOf(entity).Elem(), reflect.ValueOf(row).Elem()
+	for _, column := range f.dao.columns {
+		index := f.dao.columnToFieldIndex[column]
+		dst.Field(index).Set(deepCopy(src.Field(index)))
+	}
+	return row
+}
+
+// NewFakeTable creates a FakeTable of the entities of dao, rows are inserted into it with their keys.
+func NewFakeTable[T any](dao *Dao[T], rows ...*T) *FakeTable[T] {
+	f := &FakeTable[T]{dao: dao}
+	_, err := f.insert(rows, false, nil, false, nil)
+	must(err)
+	return f
+}
+
+// CompareValues compares two values of a column like a database, the pointers are dereferenced, and nil is less than
+// the others. The integers, floats, strings, booleans, []byte, time.Time and Decimal are supported.
+func CompareValues(a, b any) (int, error) {
+	va, vb := deref(a), deref(b)
+	switch {
+	case !va.IsValid() && !vb.IsValid():
+		return 0, nil
+	case !va.IsValid():
+		return -1, nil
+	case !vb.IsValid():
+		return 1, nil
+	}
+	if va.Type() == decimalType || vb.Type() == decimalType {
+		if da, ok := toDecimal(va); ok {
+			if db, ok := toDecimal(vb); ok {
+				return da.Cmp(db), nil
+			}
+		}
+	}
+	if ia, ok := toInt64(va.Interface()); ok {
+		if ib, ok := toInt64(vb.Interface()); ok {
+			return cmp.Compare(ia, ib), nil
+		}
+	}
+	if fa, ok := toFloat64(va); ok {
+		if fb, ok := toFloat64(vb); ok {
+			return cmp.Compare(fa, fb), nil
+		}
+	}
+	switch {
+	case va.Kind() == reflect.String && vb.Kind() == reflect.String:
+		return strings.Compare(va.String(), vb.String()), nil
+	case va.Kind() == reflect.Bool && vb.Kind() == reflect.Bool:
+		return cmp.Compare(boolInt(va.Bool()), boolInt(vb.Bool())), nil
+	}
+	if ta, ok := va.Interface().(time.Time); ok {
+		if tb, ok := vb.Interface().(time.Time); ok {
+			return ta.Compare(tb), nil
+		}
+	}
+	if ba, ok := va.Interface().([]byte); ok {
+		if bb, ok := vb.Interface().([]byte); ok {
+			return bytes.Compare(ba, bb), nil
+		}
+	}
+	if va.Type() == vb.Type() && reflect.DeepEqual(va.Interface(), vb.Interface()) {
+		return 0, nil
+	}
+	return 0, fmt.Errorf("can not compare %T with %T", a, b)
+}
+
+// MatchLike reports whether s matches the pattern of LIKE, % matches any characters and _ matches one character.
+func MatchLike(s, pattern string) bool {
+	var expr strings.Builder
+	expr.WriteString("(?s)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			expr.WriteString(".*")
+		case '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String()).MatchString(s)
+}
+
+func deref(a any) reflect.Value {
+	v := reflect.ValueOf(a)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func toInt64(a any) (int64, bool) {
+	v := deref(a)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), true
+	default:
+		return 0, false
+	}
+}
+
+func toFloat64(v reflect.Value) (float64, bool) {
+	if i, ok := toInt64(v.Interface()); ok {
+		return float64(i), true
+	}
+	if v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// toDecimal converts the Decimal, integer, float or decimal string to Decimal.
+func toDecimal(v reflect.Value) (Decimal, bool) {
+	if d, ok := v.Interface().(Decimal); ok {
+		return d, true
+	}
+	var s string
+	switch {
+	case v.CanInt(), v.CanUint():
+		s = fmt.Sprint(v.Interface())
+	case v.CanFloat():
+		s = strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case v.Kind() == reflect.String:
+		s = v.String()
+	default:
+		return Decimal{}, false
+	}
+	d, err := ParseDecimal(s)
+	return d, err == nil
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jishaocong0910/gdao"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeTable(t *testing.T) {
+	r := require.New(t)
+	dao := gdao.DaoBuilder[User]().Table("user").Build()
+	f := gdao.NewFakeTable(dao, &User{Id: gdao.P[int32](5), Name: gdao.P("foo")})
+	all := func(*User) (bool, error) { return true, nil }
+	byName := func(name string) func(*User) (bool, error) {
+		return func(u *User) (bool, error) { return f.Value(u, "name") == name, nil }
+	}
+
+	u := &User{Id: gdao.P[int32](1), Name: gdao.P("bar"), Age: gdao.P[int32](18)}
+	r.NoError(f.Insert([]*User{u}, "age"))
+	r.Equal(int32(6), *u.Id)
+	*u.Name = "changed"
+	list, err := f.Find(byName("bar"))
+	r.NoError(err)
+	r.Len(list, 1)
+	r.Nil(list[0].Age)
+	list[0].Name = gdao.P("changed")
+	list, err = f.Find(all)
+	r.NoError(err)
+	r.Equal("bar", *list[1].Name)
+
+	r.PanicsWithError("duplicate key 5 of column id", func() {
+		gdao.NewFakeTable(dao, &User{Id: gdao.P[int32](5)}, &User{Id: gdao.P[int32](5)})
+	})
+	err = gdao.NewFakeTable(dao, &User{Id: gdao.P[int32](5)}).Insert([]*User{{}})
+	r.NoError(err)
+
+	affected, err := f.Update(byName("foo"), &User{Name: gdao.P("baz"), Level: gdao.P[int32](3)}, false, []string{"status"}, "level")
+	r.NoError(err)
+	r.Equal(int64(1), affected)
+	list, err = f.Find(byName("baz"))
+	r.NoError(err)
+	r.Equal(int32(5), *list[0].Id)
+	r.Nil(list[0].Level)
+
+	f.Project(list[0], "name")
+	r.Nil(list[0].Id)
+	r.EqualError(f.Set(list[0], "unknown", 1), `unknown column "unknown"`)
+	r.EqualError(f.Set(list[0], "name", 1.5), "can not set float64 to column name of type *string")
+
+	affected, err = f.Delete(byName("baz"))
+	r.NoError(err)
+	r.Equal(int64(1), affected)
+	_, err = f.Delete(func(*User) (bool, error) { return false, errors.New("boom") })
+	r.EqualError(err, "boom")
+	list, err = f.Find(all)
+	r.NoError(err)
+	r.Len(list, 1)
+}
+
+func TestFakeTable_Atomic(t *testing.T) {
+	r := require.New(t)
+	dao := gdao.DaoBuilder[User]().Table("user").Build()
+	f := gdao.NewFakeTable(dao, &User{Id: gdao.P[int32](1), Name: gdao.P("a")}, &User{Id: gdao.P[int32](2), Name: gdao.P("b")},
+		&User{Id: gdao.P[int32](3), Name: gdao.P("c")})
+	all := func(*User) (bool, error) { return true, nil }
+	names := func() []string {
+		list, err := f.Find(all)
+		r.NoError(err)
+		var names []string
+		for _, u := range list {
+			names = append(names, *u.Name)
+		}
+		return names
+	}
+	failAt := func(id int32) func(u *User) (bool, error) {
+		return func(u *User) (bool, error) {
+			if *u.Id == id {
+				return false, errors.New("boom")
+			}
+			return *u.Id == 1, nil
+		}
+	}
+
+	_, err := f.Delete(failAt(3))
+	r.EqualError(err, "boom")
+	r.Equal([]string{"a", "b", "c"}, names())
+
+	_, err = f.Update(failAt(3), &User{Name: gdao.P("x")}, false, nil)
+	r.EqualError(err, "boom")
+	r.Equal([]string{"a", "b", "c"}, names())
+
+	_, err = f.Update(func(u *User) (bool, error) { return *u.Id == 1, nil }, &User{Id: gdao.P[int32](2), Name: gdao.P("x")}, false, nil)
+	r.ErrorIs(err, gdao.ErrDuplicateKey)
+	r.EqualError(err, "duplicate key 2 of column id")
+	r.Equal([]string{"a", "b", "c"}, names())
+
+	affected, err := f.Update(func(u *User) (bool, error) { return *u.Id == 1, nil }, &User{Id: gdao.P[int32](4), Name: gdao.P("x")}, false, nil)
+	r.NoError(err)
+	r.Equal(int64(1), affected)
+	r.Equal([]string{"x", "b", "c"}, names())
+}
+
+type TeamMember struct {
+	TeamId *int32  `gdao:"column=team_id;pk"`
+	UserId *int32  `gdao:"column=user_id;pk"`
+	Role   *string `gdao:"column=role"`
+}
+
+func TestFakeTable_PrimaryKey(t *testing.T) {
+	r := require.New(t)
+	f := gdao.NewFakeTable(gdao.DaoBuilder[TeamMember]().Table("member").Build(), &TeamMember{TeamId: gdao.P[int32](1), UserId: gdao.P[int32](2)})
+	r.NoError(f.Insert([]*TeamMember{{TeamId: gdao.P[int32](1), UserId: gdao.P[int32](3)}, {TeamId: gdao.P[int32](2), UserId: gdao.P[int32](2)}}))
+	err := f.Insert([]*TeamMember{{TeamId: gdao.P[int32](3), UserId: gdao.P[int32](3)}, {TeamId: gdao.P[int32](1), UserId: gdao.P[int32](2)}})
+	r.ErrorIs(err, gdao.ErrDuplicateKey)
+	r.EqualError(err, "duplicate key [1 2] of columns team_id, user_id")
+	list, err := f.Find(func(*TeamMember) (bool, error) { return true, nil })
+	r.NoError(err)
+	r.Len(list, 3)
+
+	c := gdao.NewFakeTable(gdao.DaoBuilder[Customer]().Table("customer").Build())
+	attrs := map[string]string{"k": "v"}
+	r.NoError(c.Insert([]*Customer{{Tags: []string{"a"}, Attrs: attrs}}))
+	attrs["k"] = "w"
+	customers, err := c.Find(func(*Customer) (bool, error) { return true, nil })
+	r.NoError(err)
+	r.Equal(map[string]string{"k": "v"}, customers[0].Attrs)
+	customers[0].Tags[0] = "b"
+	customers, err = c.Find(func(*Customer) (bool, error) { return true, nil })
+	r.NoError(err)
+	r.Equal([]string{"a"}, customers[0].Tags)
+}
+
+func TestFakeTable_Upsert(t *testing.T) {
+	r := require.New(t)
+	f := gdao.NewFakeTable(gdao.DaoBuilder[TeamMember]().Table("member").Build(),
+		&TeamMember{TeamId: gdao.P[int32](1), UserId: gdao.P[int32](2), Role: gdao.P("dev")})
+	affected, err := f.Upsert([]*TeamMember{{TeamId: gdao.P[int32](1), UserId: gdao.P[int32](2)}, {TeamId: gdao.P[int32](1), UserId: gdao.P[int32](3)}}, nil)
+	r.NoError(err)
+	r.Equal(int64(1), affected)
+
+	setRole := func(row *TeamMember) error { return f.Set(row, "role", "ops") }
+	affected, err = f.Upsert([]*TeamMember{{TeamId: gdao.P[int32](1), UserId: gdao.P[int32](2)}, {TeamId: gdao.P[int32](2), UserId: gdao.P[int32](2)}}, setRole)
+	r.NoError(err)
+	r.Equal(int64(3), affected)
+	affected, err = f.Upsert([]*TeamMember{{TeamId: gdao.P[int32](1), UserId: gdao.P[int32](2)}}, setRole)
+	r.NoError(err)
+	r.Equal(int64(0), affected)
+
+	_, err = f.Upsert([]*TeamMember{{TeamId: gdao.P[int32](3), UserId: gdao.P[int32](3)}, {TeamId: gdao.P[int32](1), UserId: gdao.P[int32](2)}},
+		func(*TeamMember) error { return errors.New("update failed") })
+	r.EqualError(err, "update failed")
+	list, err := f.Find(func(*TeamMember) (bool, error) { return true, nil })
+	r.NoError(err)
+	r.Len(list, 3)
+	r.Equal("ops", *list[0].Role)
+}
+
+func TestCompareValues(t *testing.T) {
+	r := require.New(t)
+	now := time.Now()
+	cases := []struct {
+		a, b any
+		want int
+	}{
+		{nil, (*int)(nil), 0},
+		{nil, 1, -1},
+		{gdao.P(1), nil, 1},
+		{int8(2), int64(2), 0},
+		{uint(1), 2, -1},
+		{1.5, 1, 1},
+		{"a", gdao.P("b"), -1},
+		{true, false, 1},
+		{now, now.Add(time.Second), -1},
+		{[]byte("b"), []byte("a"), 1},
+	}
+	for _, c := range cases {
+		got, err := gdao.CompareValues(c.a, c.b)
+		r.NoError(err)
+		r.Equal(c.want, got, "%v %v", c.a, c.b)
+	}
+	_, err := gdao.CompareValues("1", 1)
+	r.EqualError(err, "can not compare string with int")
+}
+
+func TestMatchLike(t *testing.T) {
+	r := require.New(t)
+	r.True(gdao.MatchLike("hello", "he%"))
+	r.True(gdao.MatchLike("hello", "%ll_"))
+	r.True(gdao.MatchLike("a.b", "a.b"))
+	r.False(gdao.MatchLike("axb", "a.b"))
+	r.False(gdao.MatchLike("hello", "he_"))
+}
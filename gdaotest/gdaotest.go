@@ -0,0 +1,440 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package gdaotest records the statements executed on a real database to a golden file, and replays them without a
+// database, so that the DAO tests are deterministic and not written by hand.
+package gdaotest
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jishaocong0910/gdao"
+)
+
+// EnvRecord is the environment variable to record the golden files again, e.g. GDAOTEST_RECORD=1 go test ./...
+const EnvRecord = "GDAOTEST_RECORD"
+
+// Open records the statements with the *sql.DB created by open if EnvRecord is set or the golden file does not
+// exist, otherwise, replays the golden file.
+func Open(t testing.TB, golden string, open func() (*sql.DB, error)) *sql.DB {
+	t.Helper()
+	if _, err := os.Stat(golden); os.Getenv(EnvRecord) != "" || errors.Is(err, os.ErrNotExist) {
+		db, err := open()
+		if err != nil {
+			t.Fatalf("gdaotest: open database: %v", err)
+		}
+		return Record(t, db, golden)
+	}
+	return Replay(t, golden)
+}
+
+// recording is the content of the golden file, the dialect of the recorded database is replayed by the driver, so
+// that gdao.DialectOf works the same in both modes.
+type recording struct {
+	Dialect    string       `json:"dialect,omitempty"`
+	Statements []*statement `json:"statements"`
+}
+
+// statement is an entry of the golden file, BEGIN, COMMIT and ROLLBACK of the transactions are also recorded.
+type statement struct {
+	Sql          string    `json:"sql"`
+	Args         []value   `json:"args,omitempty"`
+	Columns      []string  `json:"columns,omitempty"`
+	Rows         [][]value `json:"rows,omitempty"`
+	LastInsertId *int64    `json:"lastInsertId,omitempty"`
+	RowsAffected *int64    `json:"rowsAffected,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	Sentinel     string    `json:"sentinel,omitempty"`
+}
+
+// sentinels are the sentinel errors of gdao recorded by name, the replayed errors match them by errors.Is.
+var sentinels = map[string]error{
+	"ErrDuplicateKey":  gdao.ErrDuplicateKey,
+	"ErrForeignKey":    gdao.ErrForeignKey,
+	"ErrNotNull":       gdao.ErrNotNull,
+	"ErrCheck":         gdao.ErrCheck,
+	"ErrDeadlock":      gdao.ErrDeadlock,
+	"ErrLockTimeout":   gdao.ErrLockTimeout,
+	"ErrSerialization": gdao.ErrSerialization,
+}
+
+// setError records the message of err and the name of the sentinel error it is translated to.
+func (s *statement) setError(err error) {
+	if err == nil {
+		return
+	}
+	s.Error = err.Error()
+	translated := gdao.TranslateError(err)
+	for name, sentinel := range sentinels {
+		if errors.Is(translated, sentinel) {
+			s.Sentinel = name
+			return
+		}
+	}
+}
+
+// err rebuilds the recorded error.
+func (s *statement) err() error {
+	if s.Error == "" {
+		return nil
+	}
+	return &replayedError{msg: s.Error, sentinel: sentinels[s.Sentinel]}
+}
+
+// replayedError keeps the message of the recorded error, and it matches the recorded sentinel error by errors.Is.
+type replayedError struct {
+	msg      string
+	sentinel error
+}
+
+func (e *replayedError) Error() string {
+	return e.msg
+}
+
+func (e *replayedError) Is(target error) bool {
+	return e.sentinel != nil && target == e.sentinel
+}
+
+// value keeps the type of a driver.Value in JSON, the int64, string, bool and nil values are written as they are,
+// the other types are written as an object, e.g. {"float": 1.5}.
+type value struct {
+	v driver.Value
+}
+
+func (v value) MarshalJSON() ([]byte, error) {
+	switch x := v.v.(type) {
+	case nil, int64, string, bool:
+		return json.Marshal(x)
+	case float64:
+		return json.Marshal(map[string]float64{"float": x})
+	case []byte:
+		return json.Marshal(map[string]string{"bytes": base64.StdEncoding.EncodeToString(x)})
+	case time.Time:
+		return json.Marshal(map[string]string{"time": x.Format(time.RFC3339Nano)})
+	default:
+		return nil, fmt.Errorf("gdaotest: unsupported value type %T", x)
+	}
+}
+
+func (v *value) UnmarshalJSON(data []byte) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var x any
+	if err := d.Decode(&x); err != nil {
+		return err
+	}
+	switch x := x.(type) {
+	case json.Number:
+		i, err := x.Int64()
+		if err != nil {
+			return err
+		}
+		v.v = i
+	case map[string]any:
+		var err error
+		switch {
+		case x["float"] != nil:
+			v.v, err = x["float"].(json.Number).Float64()
+		case x["bytes"] != nil:
+			v.v, err = base64.StdEncoding.DecodeString(fmt.Sprint(x["bytes"]))
+		case x["time"] != nil:
+			v.v, err = time.Parse(time.RFC3339Nano, fmt.Sprint(x["time"]))
+		default:
+			err = fmt.Errorf("gdaotest: unknown value %s", data)
+		}
+		return err
+	default:
+		v.v = x
+	}
+	return nil
+}
+
+func toValues(args []driver.NamedValue) []value {
+	values := make([]value, len(args))
+	for i, a := range args {
+		values[i] = value{a.Value}
+	}
+	return values
+}
+
+func formatValues(values []value) string {
+	if len(values) == 0 {
+		return "[]"
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+func loadGolden(golden string) (*recording, error) {
+	data, err := os.ReadFile(golden)
+	if err != nil {
+		return nil, err
+	}
+	var rec recording
+	if err = json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("gdaotest: parse %s: %w", golden, err)
+	}
+	return &rec, nil
+}
+
+func saveGolden(golden string, rec *recording) error {
+	data, err := encodeGolden(rec)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(golden, data, 0o644)
+}
+
+// encodeGolden writes a field of a statement or a row per line, so that the changes of the golden file are easy to
+// review.
+func encodeGolden(rec *recording) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("{")
+	if rec.Dialect != "" {
+		data, err := json.Marshal(rec.Dialect)
+		if err != nil { // coverage-ignore
+			return nil, err
+		}
+		b.WriteString("\n  \"dialect\": " + string(data) + ",")
+	}
+	b.WriteString("\n  \"statements\": [")
+	for i, s := range rec.Statements {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n    {")
+		var fields []string
+		field := func(name string, v any) error {
+			data, err := json.Marshal(v)
+			fields = append(fields, "\n      \""+name+"\": "+string(data))
+			return err
+		}
+		err := field("sql", s.Sql)
+		if err == nil && len(s.Args) > 0 {
+			err = field("args", s.Args)
+		}
+		if err == nil && s.Columns != nil {
+			err = field("columns", s.Columns)
+		}
+		if err == nil && s.Columns != nil {
+			rows := "\n      \"rows\": ["
+			for j, row := range s.Rows {
+				data, e := json.Marshal(row)
+				if e != nil {
+					err = e
+				}
+				if j > 0 {
+					rows += ","
+				}
+				rows += "\n        " + string(data)
+			}
+			if len(s.Rows) > 0 {
+				rows += "\n      "
+			}
+			fields = append(fields, rows+"]")
+		}
+		if err == nil && s.LastInsertId != nil {
+			err = field("lastInsertId", s.LastInsertId)
+		}
+		if err == nil && s.RowsAffected != nil {
+			err = field("rowsAffected", s.RowsAffected)
+		}
+		if err == nil && s.Error != "" {
+			err = field("error", s.Error)
+		}
+		if err == nil && s.Sentinel != "" {
+			err = field("sentinel", s.Sentinel)
+		}
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(strings.Join(fields, ","))
+		b.WriteString("\n    }")
+	}
+	if len(rec.Statements) > 0 {
+		b.WriteString("\n  ")
+	}
+	b.WriteString("]\n}\n")
+	return b.Bytes(), nil
+}
+
+// diff shows the expected and actual lines, and marks the first different character of each pair of lines.
+func diff(expected, actual string) string {
+	var b strings.Builder
+	el, al := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+	for i := 0; i < max(len(el), len(al)); i++ {
+		var e, a string
+		if i < len(el) {
+			e = el[i]
+		}
+		if i < len(al) {
+			a = al[i]
+		}
+		if e == a {
+			b.WriteString("  " + e + "\n")
+			continue
+		}
+		b.WriteString("- " + e + "\n")
+		b.WriteString("+ " + a + "\n")
+		p := 0
+		for p < len(e) && p < len(a) && e[p] == a[p] {
+			p++
+		}
+		b.WriteString(strings.Repeat(" ", p+2) + "^\n")
+	}
+	return b.String()
+}
+
+// replayResult is the driver.Result of a statement.
+type replayResult struct {
+	stmt *statement
+}
+
+func (r replayResult) LastInsertId() (int64, error) {
+	if r.stmt.LastInsertId == nil {
+		return 0, errors.New("gdaotest: LastInsertId is not recorded")
+	}
+	return *r.stmt.LastInsertId, nil
+}
+
+func (r replayResult) RowsAffected() (int64, error) {
+	if r.stmt.RowsAffected == nil {
+		return 0, errors.New("gdaotest: RowsAffected is not recorded")
+	}
+	return *r.stmt.RowsAffected, nil
+}
+
+// memRows is the driver.Rows of the rows in memory.
+type memRows struct {
+	columns []string
+	rows    [][]value
+	next    int
+}
+
+func (r *memRows) Columns() []string {
+	return r.columns
+}
+
+func (r *memRows) Close() error {
+	return nil
+}
+
+func (r *memRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	for i, v := range r.rows[r.next] {
+		dest[i] = v.v
+	}
+	r.next++
+	return nil
+}
+
+// stmt prepares nothing, the query is executed by the connection when the statement is executed.
+type stmt struct {
+	query string
+	exec  func(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error)
+	rows  func(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error)
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) { // coverage-ignore
+	return s.ExecContext(context.Background(), named(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) { // coverage-ignore
+	return s.QueryContext(context.Background(), named(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.exec(ctx, s.query, args)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.rows(ctx, s.query, args)
+}
+
+func named(args []driver.Value) []driver.NamedValue { // coverage-ignore
+	nv := make([]driver.NamedValue, len(args))
+	for i, a := range args {
+		nv[i] = driver.NamedValue{Ordinal: i + 1, Value: a}
+	}
+	return nv
+}
+
+// tx ends the transaction by end, which is called with "COMMIT" or "ROLLBACK".
+type tx struct {
+	end func(op string) error
+}
+
+func (t tx) Commit() error {
+	return t.end("COMMIT")
+}
+
+func (t tx) Rollback() error {
+	return t.end("ROLLBACK")
+}
+
+// gdaotestDriver is the driver of gdaotest.Replay, it reports the recorded dialect to gdao.DialectOf.
+type gdaotestDriver struct {
+	dialect gdao.Dialect
+}
+
+func (gdaotestDriver) Open(string) (driver.Conn, error) { // coverage-ignore
+	return nil, errors.New("gdaotest: the driver can only be used by gdaotest.Replay")
+}
+
+func (d gdaotestDriver) Dialect() gdao.Dialect {
+	return d.dialect
+}
+
+func int64Ptr(i int64, err error) *int64 {
+	if err != nil {
+		return nil
+	}
+	return &i
+}
+
+func ordinal(i int) string {
+	return "#" + strconv.Itoa(i+1)
+}
@@ -0,0 +1,203 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdaotest_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jishaocong0910/gdao"
+	"github.com/jishaocong0910/gdao/gdaotest"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+type User struct {
+	Id     *int64   `gdao:"column=id;auto"`
+	Name   *string  `gdao:"column=name"`
+	Score  *float64 `gdao:"column=score"`
+	Avatar []byte   `gdao:"column=avatar"`
+}
+
+// useDao executes the statements and returns the results for comparing.
+func useDao(r *require.Assertions, db *sql.DB) []string {
+	gdao.Config(gdao.Cfg{DefaultDB: db})
+	dao := gdao.DaoBuilder[User]().Build()
+	var results []string
+	u := &User{Name: gdao.P("lucy"), Score: gdao.P(1.0), Avatar: []byte{1, 2}}
+	affected, err := dao.Exec().Entities(u).LastInsertIdAs(gdao.LastInsertIdAs_.FIRST_ID).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+		b.Write("INSERT INTO user(name, score, avatar) VALUES(?, ?, ?)", u.Name, u.Score, u.Avatar)
+	}).Do()
+	r.NoError(err)
+	results = append(results, fmt.Sprint(affected, *u.Id))
+
+	r.NoError(gdao.Tx(nil, func(ctx context.Context) error {
+		_, err := dao.Exec().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("UPDATE user SET score=? WHERE id=?", 2.5, u.Id)
+		}).Do()
+		return err
+	}))
+
+	_, list, err := dao.Query().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+		b.Write("SELECT id, name, score, avatar FROM user WHERE name=?", "lucy")
+	}).Do()
+	r.NoError(err)
+	for _, e := range list {
+		results = append(results, fmt.Sprintf("%d %s %v %v", *e.Id, *e.Name, *e.Score, e.Avatar))
+	}
+
+	_, err = dao.Exec().BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+		b.Write("INSERT INTO user(id) VALUES(?)", u.Id)
+	}).Do()
+	r.Error(err)
+	results = append(results, err.Error(), fmt.Sprint(errors.Is(err, gdao.ErrDuplicateKey)), gdao.DialectOf(db).String())
+	return results
+}
+
+func TestRecordReplay(t *testing.T) {
+	r := require.New(t)
+	golden := filepath.Join(t.TempDir(), "testdata", "user.golden")
+	var recorded, replayed []string
+	t.Run("record", func(t *testing.T) {
+		db := gdaotest.Open(t, golden, func() (*sql.DB, error) {
+			db, err := sql.Open("sqlite3", ":memory:")
+			if err == nil {
+				_, err = db.Exec("CREATE TABLE user (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, score REAL, avatar BLOB)")
+			}
+			return db, err
+		})
+		recorded = useDao(require.New(t), db)
+	})
+	data, err := os.ReadFile(golden)
+	r.NoError(err)
+	r.Contains(string(data), `"sql": "UPDATE user SET score=? WHERE id=?"`)
+	r.Contains(string(data), `"sql": "COMMIT"`)
+	r.Contains(string(data), `"args": [{"float":2.5},1]`)
+	r.Contains(string(data), `"dialect": "SQLITE"`)
+	r.Contains(string(data), `"sentinel": "ErrDuplicateKey"`)
+
+	t.Run("replay", func(t *testing.T) {
+		db := gdaotest.Open(t, golden, func() (*sql.DB, error) {
+			panic("the database must not be opened in replay mode")
+		})
+		replayed = useDao(require.New(t), db)
+	})
+	r.Equal([]string{"1 1", "1 lucy 2.5 [1 2]", "UNIQUE constraint failed: user.id", "true", "SQLITE"}, recorded)
+	r.Equal(recorded, replayed)
+}
+
+type mockT struct {
+	testing.TB
+	errs []string
+}
+
+func (m *mockT) Error(args ...any) {
+	m.errs = append(m.errs, fmt.Sprint(args...))
+}
+
+func (m *mockT) Errorf(format string, args ...any) {
+	m.errs = append(m.errs, fmt.Sprintf(format, args...))
+}
+
+func TestReplay_Mismatch(t *testing.T) {
+	r := require.New(t)
+	golden := filepath.Join(t.TempDir(), "user.golden")
+	r.NoError(os.WriteFile(golden, []byte(`{"statements": [
+  {"sql": "SELECT name FROM user WHERE id = ?", "args": [1], "columns": ["name"], "rows": [["lucy"]]},
+  {"sql": "DELETE FROM user"}
+]}`), 0o644))
+	m := &mockT{}
+	t.Run("replay", func(t *testing.T) {
+		m.TB = t
+		db := gdaotest.Replay(m, golden)
+		_, err := db.Query("SELECT name FROM user WHERE id=?", 1)
+		r.Error(err)
+		var name string
+		r.NoError(db.QueryRow("SELECT name FROM user WHERE id = ?", 1).Scan(&name))
+		r.Equal("lucy", name)
+	})
+	r.Len(m.errs, 2)
+	r.Equal(`gdaotest: statement #1 does not match `+golden+`:
+- SELECT name FROM user WHERE id = ?
++ SELECT name FROM user WHERE id=?
+                                ^
+  [1]
+`, m.errs[0])
+	r.Equal("gdaotest: 1 statements of "+golden+" are not executed, the next is #2: DELETE FROM user", m.errs[1])
+}
+
+func TestWithRollback(t *testing.T) {
+	r := require.New(t)
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "user.db")+"?_busy_timeout=5000&_txlock=immediate")
+	r.NoError(err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE user (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, score REAL, avatar BLOB)")
+	r.NoError(err)
+	gdao.Config(gdao.Cfg{DefaultDB: db})
+	dao := gdao.DaoBuilder[User]().Build()
+	insert := func(ctx context.Context, name string) error {
+		_, err := dao.Exec().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("INSERT INTO user(name) VALUES(?)", name)
+		}).Do()
+		return err
+	}
+	count := func() int {
+		var n int
+		r.NoError(db.QueryRow("SELECT COUNT(*) FROM user").Scan(&n))
+		return n
+	}
+	names := func(r *require.Assertions, ctx context.Context) []string {
+		_, list, err := dao.Query().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("SELECT name FROM user ORDER BY id")
+		}).Do()
+		r.NoError(err)
+		var names []string
+		for _, u := range list {
+			names = append(names, *u.Name)
+		}
+		return names
+	}
+
+	gdaotest.WithRollback(t, db, func(ctx context.Context) {
+		r.NoError(insert(ctx, "foo"))
+		r.NoError(gdao.Tx(ctx, func(ctx context.Context) error {
+			return insert(ctx, "bar")
+		}, gdao.WithPropagation(gdao.Propagation_.REQUIRES_NEW)))
+		r.Error(gdao.Tx(ctx, func(ctx context.Context) error {
+			r.NoError(insert(ctx, "baz"))
+			return errors.New("rollback to the savepoint")
+		}, gdao.WithPropagation(gdao.Propagation_.REQUIRED)))
+		r.Equal([]string{"foo", "bar"}, names(r, ctx))
+	})
+	r.Equal(0, count())
+
+	t.Run("parallel", func(t *testing.T) {
+		for _, name := range []string{"foo", "bar"} {
+			gdaotest.RunWithRollback(t, db, name, func(t *testing.T, ctx context.Context) {
+				r := require.New(t)
+				r.NoError(insert(ctx, name))
+				r.Equal([]string{name}, names(r, ctx))
+			})
+		}
+	})
+	r.Equal(0, count())
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdaotest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+	"testing"
+
+	"github.com/jishaocong0910/gdao"
+)
+
+// Record returns a *sql.DB executing the statements on db, the statements, args and results are written to the
+// golden file when the test finishes. The returned *sql.DB has one connection, so that the statements are recorded in
+// order.
+func Record(t testing.TB, db *sql.DB, golden string) *sql.DB {
+	t.Helper()
+	r := &recorder{db: db}
+	rdb := sql.OpenDB(r)
+	rdb.SetMaxOpenConns(1)
+	t.Cleanup(func() {
+		_ = rdb.Close()
+		rec := &recording{Statements: r.stmts}
+		if d := gdao.DialectOf(db); !d.IsUndefined() {
+			rec.Dialect = d.String()
+		}
+		if err := saveGolden(golden, rec); err != nil {
+			t.Errorf("gdaotest: save %s: %v", golden, err)
+		}
+	})
+	return rdb
+}
+
+type recorder struct {
+	db    *sql.DB
+	mu    sync.Mutex
+	stmts []*statement
+}
+
+func (r *recorder) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil { // coverage-ignore
+		return nil, err
+	}
+	return &recordConn{r: r, conn: conn}, nil
+}
+
+// Driver returns the driver of the recorded database, so that gdao.DialectOf returns its dialect.
+func (r *recorder) Driver() driver.Driver {
+	return r.db.Driver()
+}
+
+func (r *recorder) add(s *statement) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stmts = append(r.stmts, s)
+}
+
+// recordConn executes the statements on a connection of the real database, and the statements in a transaction are
+// executed by the transaction.
+type recordConn struct {
+	r    *recorder
+	conn *sql.Conn
+	tx   *sql.Tx
+}
+
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func (c *recordConn) executor() executor {
+	if c.tx != nil {
+		return c.tx
+	}
+	return c.conn
+}
+
+func (c *recordConn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{query: query, exec: c.exec, rows: c.query}, nil
+}
+
+func (c *recordConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *recordConn) Begin() (driver.Tx, error) { // coverage-ignore
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *recordConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	t, err := c.conn.BeginTx(ctx, &sql.TxOptions{Isolation: sql.IsolationLevel(opts.Isolation), ReadOnly: opts.ReadOnly})
+	begin := &statement{Sql: "BEGIN"}
+	begin.setError(err)
+	c.r.add(begin)
+	if err != nil { // coverage-ignore
+		return nil, err
+	}
+	c.tx = t
+	return tx{end: func(op string) error {
+		var err error
+		if op == "COMMIT" {
+			err = t.Commit()
+		} else {
+			err = t.Rollback()
+		}
+		c.tx = nil
+		end := &statement{Sql: op}
+		end.setError(err)
+		c.r.add(end)
+		return err
+	}}, nil
+}
+
+func (c *recordConn) exec(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s := &statement{Sql: query, Args: toValues(args)}
+	defer c.r.add(s)
+	result, err := c.executor().ExecContext(ctx, query, anyArgs(args)...)
+	if err != nil {
+		s.setError(err)
+		return nil, err
+	}
+	s.LastInsertId = int64Ptr(result.LastInsertId())
+	s.RowsAffected = int64Ptr(result.RowsAffected())
+	return replayResult{stmt: s}, nil
+}
+
+func (c *recordConn) query(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	s := &statement{Sql: query, Args: toValues(args)}
+	defer c.r.add(s)
+	rows, err := c.executor().QueryContext(ctx, query, anyArgs(args)...)
+	if err != nil {
+		s.setError(err)
+		return nil, err
+	}
+	defer rows.Close()
+	if s.Columns, err = rows.Columns(); err != nil { // coverage-ignore
+		s.setError(err)
+		return nil, err
+	}
+	for rows.Next() {
+		dests := make([]any, len(s.Columns))
+		for i := range dests {
+			dests[i] = new(any)
+		}
+		if err = rows.Scan(dests...); err != nil { // coverage-ignore
+			s.setError(err)
+			return nil, err
+		}
+		row := make([]value, len(dests))
+		for i, d := range dests {
+			row[i] = value{*d.(*any)}
+		}
+		s.Rows = append(s.Rows, row)
+	}
+	if err = rows.Err(); err != nil { // coverage-ignore
+		s.setError(err)
+		return nil, err
+	}
+	return &memRows{columns: s.Columns, rows: s.Rows}, nil
+}
+
+func anyArgs(args []driver.NamedValue) []any {
+	a := make([]any, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			a[i] = sql.Named(arg.Name, arg.Value)
+		} else {
+			a[i] = arg.Value
+		}
+	}
+	return a
+}
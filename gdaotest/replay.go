@@ -0,0 +1,132 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdaotest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/jishaocong0910/gdao"
+)
+
+// Replay returns a *sql.DB serving the results of the golden file in order without a database. A statement not
+// matching the golden file fails the test and returns an error with the diff, and the test also fails if some
+// statements of the golden file are not executed.
+func Replay(t testing.TB, golden string) *sql.DB {
+	t.Helper()
+	rec, err := loadGolden(golden)
+	if err != nil {
+		t.Fatalf("gdaotest: load %s: %v", golden, err)
+	}
+	r := &replayer{t: t, golden: golden, dialect: gdao.Dialect_.OfString(rec.Dialect), stmts: rec.Statements}
+	db := sql.OpenDB(r)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() {
+		_ = db.Close()
+		if r.next < len(r.stmts) {
+			t.Errorf("gdaotest: %d statements of %s are not executed, the next is %s: %s",
+				len(r.stmts)-r.next, golden, ordinal(r.next), r.stmts[r.next].Sql)
+		}
+	})
+	return db
+}
+
+type replayer struct {
+	t       testing.TB
+	golden  string
+	dialect gdao.Dialect
+	mu      sync.Mutex
+	stmts   []*statement
+	next    int
+}
+
+func (r *replayer) Connect(context.Context) (driver.Conn, error) {
+	return replayConn{r: r}, nil
+}
+
+func (r *replayer) Driver() driver.Driver {
+	return gdaotestDriver{dialect: r.dialect}
+}
+
+// take returns the next statement of the golden file if it matches the executed one.
+func (r *replayer) take(query string, args []value) (*statement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	actual := query + "\n" + formatValues(args)
+	if r.next >= len(r.stmts) {
+		err := fmt.Errorf("gdaotest: statement %s is not in %s:\n%s", ordinal(r.next), r.golden, diff("", actual))
+		r.t.Error(err)
+		return nil, err
+	}
+	s := r.stmts[r.next]
+	if expected := s.Sql + "\n" + formatValues(s.Args); expected != actual {
+		err := fmt.Errorf("gdaotest: statement %s does not match %s:\n%s", ordinal(r.next), r.golden, diff(expected, actual))
+		r.t.Error(err)
+		return nil, err
+	}
+	r.next++
+	if err := s.err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+type replayConn struct {
+	r *replayer
+}
+
+func (c replayConn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{query: query, exec: c.exec, rows: c.query}, nil
+}
+
+func (c replayConn) Close() error {
+	return nil
+}
+
+func (c replayConn) Begin() (driver.Tx, error) { // coverage-ignore
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c replayConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	if _, err := c.r.take("BEGIN", nil); err != nil {
+		return nil, err
+	}
+	return tx{end: func(op string) error {
+		_, err := c.r.take(op, nil)
+		return err
+	}}, nil
+}
+
+func (c replayConn) exec(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s, err := c.r.take(query, toValues(args))
+	if err != nil {
+		return nil, err
+	}
+	return replayResult{stmt: s}, nil
+}
+
+func (c replayConn) query(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	s, err := c.r.take(query, toValues(args))
+	if err != nil {
+		return nil, err
+	}
+	return &memRows{columns: s.Columns, rows: s.Rows}, nil
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdaotest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/jishaocong0910/gdao"
+)
+
+// WithRollback calls do with a context bound to a transaction of db, and the transaction is always rolled back, so
+// that the tests do not leak data into each other. The gdao.Tx calls directly in do create savepoints in the
+// transaction instead of committing. The DAOs must execute the statements with the context to use the transaction.
+func WithRollback(t testing.TB, db *sql.DB, do func(ctx context.Context)) {
+	t.Helper()
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("gdaotest: begin transaction: %v", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil {
+			t.Errorf("gdaotest: rollback transaction: %v", err)
+		}
+		gdao.BoundTxDone(tx)
+	}()
+	do(gdao.SetRollbackTx(context.Background(), db, tx))
+}
+
+// RunWithRollback runs do as a parallel subtest of t with WithRollback. The transaction of each subtest holds a
+// connection of its own until it is rolled back, so that the subtests are isolated by the database.
+func RunWithRollback(t *testing.T, db *sql.DB, name string, do func(t *testing.T, ctx context.Context)) bool {
+	t.Helper()
+	return t.Run(name, func(t *testing.T) {
+		t.Parallel()
+		WithRollback(t, db, func(ctx context.Context) {
+			do(t, ctx)
+		})
+	})
+}
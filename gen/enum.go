@@ -41,7 +41,9 @@ type _mappingType struct {
 	*e.Enum__[mappingType]
 	base,
 	slice,
-	convert mappingType
+	convert,
+	json,
+	decimal mappingType
 }
 
 var mappingType_ = e.NewEnum[mappingType](_mappingType{})
@@ -52,10 +52,12 @@ type DaoCfg struct {
 type TableCfg struct {
 	// 需要生成的表
 	Tables Tables
-	// 指定表字段映射实体字段类型，使用函数 [Mapping]、[MappingSlice] 或 [MappingConvert] 指定
+	// 指定表字段映射实体字段类型，使用函数 [Mapping]、[MappingSlice]、[MappingConvert]、[MappingJSON] 或 [MappingDecimal] 指定
 	Mappers Mappers
 	// 指定表忽略的字段，key为表名，value为列名
 	Ignores Ignores
+	// 指定所有表的decimal/numeric列映射实体字段类型，默认为*float64，使用函数 [MappingDecimal] 或 [MappingConvert] 等指定，Mappers优先
+	Decimal mapping
 }
 
 type baseDaoTplParam struct {
@@ -84,6 +86,7 @@ type daoTplParam struct {
 type fieldTplParam struct {
 	Column            string
 	FieldName         string
+	IsPrimaryKey      bool
 	FieldType         string
 	IsAutoIncrement   bool
 	IsNotNull         bool
@@ -91,6 +94,9 @@ type fieldTplParam struct {
 	AutoIncrementStep int
 	Comment           string
 	Valid             bool
+	IsJSON            bool
+
+	isDecimal bool
 }
 
 type Tables []string
@@ -140,6 +146,17 @@ func MappingConvert[T any]() mapping {
 	return mapping{t: t, mt: mappingType_.convert}
 }
 
+// MappingJSON 映射为JSON字段，T可以是结构体、结构体指针、map或切片，读写时使用 [gdao.Cfg] 的JSONCodec序列化
+func MappingJSON[T any]() mapping {
+	var t T
+	return mapping{t: t, mt: mappingType_.json}
+}
+
+// MappingDecimal 映射为 [gdao.Decimal] 指针，精确读写decimal/numeric列，列值为NULL时字段为nil
+func MappingDecimal() mapping {
+	return mapping{mt: mappingType_.decimal}
+}
+
 func must(err error) {
 	if err != nil { // coverage-ignore
 		panic(err)
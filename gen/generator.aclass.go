@@ -185,14 +185,19 @@ func (this *generator__) ignoreFields(table string, fields []fieldTplParam) []fi
 
 func (this *generator__) mappingFields(table string, fields []fieldTplParam) ([]string, error) {
 	mappings := this.cfg.TableCfg.Mappers[table]
-	if mappings == nil {
+	decimal := this.cfg.TableCfg.Decimal
+	if mappings == nil && decimal.mt.IsUndefined() {
 		return nil, nil
 	}
 	pkgNameToPaths := make(map[string]string, len(mappings))
 
 	for i := 0; i < len(fields); i++ {
 		f := &fields[i]
-		if m, ok := mappings[f.Column]; ok {
+		m, ok := mappings[f.Column]
+		if !ok && f.isDecimal && !decimal.mt.IsUndefined() {
+			m, ok = decimal, true
+		}
+		if ok {
 			switch m.mt.String() {
 			case mappingType_.base.String():
 				f.FieldType = "*" + reflect.TypeOf(m.t).String()
@@ -219,6 +224,23 @@ func (this *generator__) mappingFields(table string, fields []fieldTplParam) ([]
 				} else { // coverage-ignore
 					return nil, errors.New("the mapping of table \"" + table + "\"'s column \"" + f.Column + "\" is invalid implementing gdao.Convert")
 				}
+			case mappingType_.json.String():
+				ft := reflect.TypeOf(m.t)
+				if ft.Kind() == reflect.Struct {
+					ft = reflect.PointerTo(ft)
+				}
+				switch {
+				case ft.Kind() == reflect.Pointer && ft.Elem().Kind() == reflect.Struct, ft.Kind() == reflect.Map, ft.Kind() == reflect.Slice:
+					f.FieldType = this.jsonFieldType(ft, pkgNameToPaths)
+					f.IsJSON = true
+					f.Valid = true
+				default: // coverage-ignore
+					return nil, errors.New("the JSON mapping of table \"" + table + "\"'s column \"" + f.Column + "\" must be a struct, a pointer to struct, a map or a slice")
+				}
+			case mappingType_.decimal.String():
+				f.FieldType = "*gdao.Decimal"
+				f.Valid = true
+				pkgNameToPaths["gdao"] = "github.com/jishaocong0910/gdao"
 			}
 		}
 	}
@@ -233,6 +255,31 @@ func (this *generator__) mappingFields(table string, fields []fieldTplParam) ([]
 	return imports, nil
 }
 
+// jsonFieldType returns the type name of the JSON field, the packages of the named types are imported.
+func (this *generator__) jsonFieldType(ft reflect.Type, pkgNameToPaths map[string]string) string {
+	if ft.Name() != "" {
+		if ft.PkgPath() == "" {
+			return ft.String()
+		}
+		pkgPath, pkgName, typeName := this.determineFieldType(ft, pkgNameToPaths)
+		pkgNameToPaths[pkgName] = pkgPath
+		return pkgName + "." + typeName
+	}
+	switch ft.Kind() {
+	case reflect.Pointer:
+		return "*" + this.jsonFieldType(ft.Elem(), pkgNameToPaths)
+	case reflect.Slice:
+		return "[]" + this.jsonFieldType(ft.Elem(), pkgNameToPaths)
+	case reflect.Map:
+		return "map[" + this.jsonFieldType(ft.Key(), pkgNameToPaths) + "]" + this.jsonFieldType(ft.Elem(), pkgNameToPaths)
+	case reflect.Interface:
+		if ft.NumMethod() == 0 {
+			return "any"
+		}
+	}
+	return ft.String() // coverage-ignore
+}
+
 func (this *generator__) determineFieldType(ft reflect.Type, pkgNameToPaths map[string]string) (string, string, string) {
 	var pkgPath string
 	if ft.Kind() == reflect.Pointer {
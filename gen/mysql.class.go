@@ -46,12 +46,13 @@ func (this *mySqlGenerator) getTableInfo(table string) ([]fieldTplParam, string,
 		tableComment string
 	)
 
-	rows := mustReturn(this.db.Query("SELECT COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, EXTRA = 'auto_increment', IS_NULLABLE = 'NO', COLUMN_DEFAULT IS NOT NULL, COLUMN_COMMENT FROM information_schema.columns WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION", this.database, table))
+	rows := mustReturn(this.db.Query("SELECT COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, EXTRA = 'auto_increment', IS_NULLABLE = 'NO', COLUMN_DEFAULT IS NOT NULL, COLUMN_COMMENT, COLUMN_KEY = 'PRI' FROM information_schema.columns WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION", this.database, table))
 	defer rows.Close()
 	for rows.Next() {
 		exists = true
 		var (
 			fieldType string
+			isDecimal bool
 			// 扫描的字段
 			column          string
 			dataType        string
@@ -60,8 +61,9 @@ func (this *mySqlGenerator) getTableInfo(table string) ([]fieldTplParam, string,
 			isNotNull       bool
 			hasDefaultValue bool
 			comment         string
+			isPrimaryKey    bool
 		)
-		must(rows.Scan(&column, &dataType, &columnType, &isAutoIncrement, &isNotNull, &hasDefaultValue, &comment))
+		must(rows.Scan(&column, &dataType, &columnType, &isAutoIncrement, &isNotNull, &hasDefaultValue, &comment, &isPrimaryKey))
 
 		dataType = strings.ToLower(dataType)
 		columnType = strings.ToLower(columnType)
@@ -100,8 +102,11 @@ func (this *mySqlGenerator) getTableInfo(table string) ([]fieldTplParam, string,
 			} else {
 				fieldType = "*int64"
 			}
-		case "double", "decimal":
+		case "double":
 			fieldType = "*float64"
+		case "decimal":
+			fieldType = "*float64"
+			isDecimal = true
 		case "float":
 			fieldType = "*float32"
 		case "varchar", "char", "text", "tinytext", "mediumtext", "longtext", "enum", "json", "set", "time":
@@ -118,11 +123,13 @@ func (this *mySqlGenerator) getTableInfo(table string) ([]fieldTplParam, string,
 			Column:          column,
 			FieldName:       fieldNameMapper.Convert(column),
 			FieldType:       fieldType,
+			IsPrimaryKey:    isPrimaryKey,
 			IsAutoIncrement: isAutoIncrement,
 			IsNotNull:       isNotNull,
 			HasDefaultValue: hasDefaultValue,
 			Comment:         comment,
 			Valid:           fieldType != "any",
+			isDecimal:       isDecimal,
 		}
 		fields = append(fields, f)
 	}
@@ -46,12 +46,13 @@ func (this *oracleGenerator) getTableInfo(table string) ([]fieldTplParam, string
 		tableComment string
 	)
 
-	rows := mustReturn(this.db.Query(`SELECT c.column_name, c.data_type, c.data_precision, c.data_scale, c.char_length, c.nullable = 'N', c.data_default IS NOT NULL, c2.comments FROM user_tab_columns c LEFT JOIN user_col_comments c2 ON c.table_name =c2.table_name AND c.COLUMN_NAME =c2.COLUMN_NAME WHERE c.table_name = :1 ORDER BY c.column_id`, strings.ToUpper(table)))
+	rows := mustReturn(this.db.Query(`SELECT c.column_name, c.data_type, c.data_precision, c.data_scale, c.char_length, c.nullable = 'N', c.data_default IS NOT NULL, c2.comments, (SELECT COUNT(*) FROM user_constraints k JOIN user_cons_columns kc ON k.constraint_name = kc.constraint_name WHERE k.constraint_type = 'P' AND k.table_name = c.table_name AND kc.column_name = c.column_name) > 0 FROM user_tab_columns c LEFT JOIN user_col_comments c2 ON c.table_name =c2.table_name AND c.COLUMN_NAME =c2.COLUMN_NAME WHERE c.table_name = :1 ORDER BY c.column_id`, strings.ToUpper(table)))
 	defer rows.Close()
 	for rows.Next() {
 		exists = true
 		var (
 			fieldType string
+			isDecimal bool
 			// 扫描的字段
 			column          string
 			dataType        string
@@ -61,8 +62,9 @@ func (this *oracleGenerator) getTableInfo(table string) ([]fieldTplParam, string
 			isNotNull       bool
 			hasDefaultValue bool
 			comment         *string
+			isPrimaryKey    bool
 		)
-		must(rows.Scan(&column, &dataType, &precision, &scale, &charLength, &isNotNull, &hasDefaultValue, &comment))
+		must(rows.Scan(&column, &dataType, &precision, &scale, &charLength, &isNotNull, &hasDefaultValue, &comment, &isPrimaryKey))
 		dataType = strings.ToUpper(dataType)
 		if strings.HasPrefix(dataType, "TIMESTAMP") {
 			dataType = "TIMESTAMP"
@@ -85,6 +87,7 @@ func (this *oracleGenerator) getTableInfo(table string) ([]fieldTplParam, string
 				fieldType = "*int64"
 			} else {
 				fieldType = "*float64"
+				isDecimal = true
 			}
 		case "FLOAT", "BINARY_DOUBLE":
 			fieldType = "*float64"
@@ -100,10 +103,12 @@ func (this *oracleGenerator) getTableInfo(table string) ([]fieldTplParam, string
 			Column:          column,
 			FieldName:       fieldNameMapper.Convert(column),
 			FieldType:       fieldType,
+			IsPrimaryKey:    isPrimaryKey,
 			IsNotNull:       isNotNull,
 			HasDefaultValue: hasDefaultValue,
 			Comment:         *comment,
 			Valid:           fieldType != "any",
+			isDecimal:       isDecimal,
 		}
 		fields = append(fields, f)
 	}
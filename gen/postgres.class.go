@@ -48,12 +48,13 @@ func (g postgresGenerator) getTableInfo(table string) ([]fieldTplParam, string,
 		tableComment string
 	)
 
-	rows := mustReturn(g.db.Query("SELECT i.column_name,i.udt_name,i.is_identity,i.is_nullable='NO',i.column_default,p.attndims,p.description FROM information_schema.columns i JOIN (SELECT n.nspname,c.relname,a.attname,a.attndims,d.description FROM pg_namespace n JOIN pg_class c ON n.oid = c.relnamespace JOIN pg_attribute a ON a.attrelid = c.oid LEFT JOIN pg_description d ON d.objoid = c.oid AND d.objsubid = a.attnum WHERE a.attnum > 0 AND NOT a.attisdropped) p ON p.nspname=i.table_schema and p.relname=i.table_name and p.attname=i.column_name WHERE i.table_catalog = $1 AND i.table_schema = $2 AND i.table_name = $3 ORDER BY i.ordinal_position", g.database, g.schema, table))
+	rows := mustReturn(g.db.Query("SELECT i.column_name,i.udt_name,i.is_identity,i.is_nullable='NO',i.column_default,p.attndims,p.description,p.is_pk FROM information_schema.columns i JOIN (SELECT n.nspname,c.relname,a.attname,a.attndims,d.description,EXISTS (SELECT 1 FROM pg_index x WHERE x.indrelid = c.oid AND x.indisprimary AND a.attnum = ANY(x.indkey)) AS is_pk FROM pg_namespace n JOIN pg_class c ON n.oid = c.relnamespace JOIN pg_attribute a ON a.attrelid = c.oid LEFT JOIN pg_description d ON d.objoid = c.oid AND d.objsubid = a.attnum WHERE a.attnum > 0 AND NOT a.attisdropped) p ON p.nspname=i.table_schema and p.relname=i.table_name and p.attname=i.column_name WHERE i.table_catalog = $1 AND i.table_schema = $2 AND i.table_name = $3 ORDER BY i.ordinal_position", g.database, g.schema, table))
 	defer rows.Close()
 	for rows.Next() {
 		exists = true
 		var (
 			fieldType       string
+			isDecimal       bool
 			hasDefaultValue bool
 			isAutoIncrement bool
 			// 扫描的字段
@@ -64,8 +65,9 @@ func (g postgresGenerator) getTableInfo(table string) ([]fieldTplParam, string,
 			columnDefault *string
 			attndims      int
 			description   *string
+			isPrimaryKey  bool
 		)
-		must(rows.Scan(&column, &udtName, &isIdentity, &isNotNull, &columnDefault, &attndims, &description))
+		must(rows.Scan(&column, &udtName, &isIdentity, &isNotNull, &columnDefault, &attndims, &description, &isPrimaryKey))
 		if description == nil {
 			description = gdao.P("")
 		}
@@ -93,8 +95,11 @@ func (g postgresGenerator) getTableInfo(table string) ([]fieldTplParam, string,
 			fieldType = "*int16"
 		case "time", "timetz", "date", "timestamp", "timestamptz":
 			fieldType = "*time.Time"
-		case "float8", "numeric", "money":
+		case "float8", "money":
 			fieldType = "*float64"
+		case "numeric":
+			fieldType = "*float64"
+			isDecimal = attndims == 0
 		case "float4":
 			fieldType = "*float32"
 		case "bool":
@@ -117,11 +122,13 @@ func (g postgresGenerator) getTableInfo(table string) ([]fieldTplParam, string,
 			Column:          column,
 			FieldName:       fieldNameMapper.Convert(column),
 			FieldType:       fieldType,
+			IsPrimaryKey:    isPrimaryKey,
 			IsNotNull:       isNotNull,
 			HasDefaultValue: hasDefaultValue,
 			Comment:         *description,
 			Valid:           fieldType != "any",
 			IsAutoIncrement: isAutoIncrement,
+			isDecimal:       isDecimal,
 		}
 		fields = append(fields, f)
 	}
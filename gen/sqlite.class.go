@@ -51,6 +51,7 @@ func (this *sqliteGenerator) getTableInfo(table string) ([]fieldTplParam, string
 		exists = true
 		var (
 			fieldType       string
+			isDecimal       bool
 			hasDefaultValue bool
 			isAutoIncrement bool
 			// 扫描的字段
@@ -86,8 +87,11 @@ func (this *sqliteGenerator) getTableInfo(table string) ([]fieldTplParam, string
 			fieldType = "*uint32"
 		case "UNSIGNED BIG INT":
 			fieldType = "*uint64"
-		case "REAL", "NUMERIC", "DOUBLE", "DOUBLE PRECISION", "FLOAT", "DECIMAL":
+		case "REAL", "DOUBLE", "DOUBLE PRECISION", "FLOAT":
 			fieldType = "*float64"
+		case "NUMERIC", "DECIMAL":
+			fieldType = "*float64"
+			isDecimal = true
 		case "BOOLEAN":
 			fieldType = "*bool"
 		case "DATETIME", "TIMESTAMP", "TIMESTAMP WITH TIME ZONE", "TIME WITH TIME ZONE":
@@ -102,10 +106,12 @@ func (this *sqliteGenerator) getTableInfo(table string) ([]fieldTplParam, string
 			Column:          column,
 			FieldName:       fieldNameMapper.Convert(column),
 			FieldType:       fieldType,
+			IsPrimaryKey:    pk != 0,
 			IsAutoIncrement: isAutoIncrement,
 			IsNotNull:       isNotNull,
 			HasDefaultValue: hasDefaultValue,
 			Valid:           fieldType != "any",
+			isDecimal:       isDecimal,
 		}
 		fields = append(fields, f)
 	}
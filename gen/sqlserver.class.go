@@ -46,12 +46,13 @@ func (g sqlServerGenerator) getTableInfo(table string) ([]fieldTplParam, string,
 		tableComment string
 	)
 
-	rows := mustReturn(g.db.Query("SELECT B.name, C.increment_value, D.data_type, CASE D.is_nullable WHEN 'NO' THEN 1 ELSE 0 END AS is_notnull, CASE WHEN D.column_default IS NOT NULL THEN 1 ELSE 0 END AS has_default, E.value AS comment FROM sys.tables A LEFT JOIN sys.columns B ON A.object_id = B.object_id LEFT JOIN sys.identity_columns C ON A.object_id =C.object_id and B.name=C.name LEFT JOIN information_schema.columns D ON B.name = D.column_name LEFT JOIN sys.extended_properties E ON B.object_id = E.major_id AND B.column_id = E.minor_id WHERE A.name = :1 AND D.table_name = :2 ORDER BY D.ordinal_position", table, table))
+	rows := mustReturn(g.db.Query("SELECT B.name, C.increment_value, D.data_type, CASE D.is_nullable WHEN 'NO' THEN 1 ELSE 0 END AS is_notnull, CASE WHEN D.column_default IS NOT NULL THEN 1 ELSE 0 END AS has_default, E.value AS comment, CASE WHEN EXISTS (SELECT 1 FROM sys.indexes I JOIN sys.index_columns IC ON I.object_id = IC.object_id AND I.index_id = IC.index_id WHERE I.is_primary_key = 1 AND I.object_id = A.object_id AND IC.column_id = B.column_id) THEN 1 ELSE 0 END AS is_pk FROM sys.tables A LEFT JOIN sys.columns B ON A.object_id = B.object_id LEFT JOIN sys.identity_columns C ON A.object_id =C.object_id and B.name=C.name LEFT JOIN information_schema.columns D ON B.name = D.column_name LEFT JOIN sys.extended_properties E ON B.object_id = E.major_id AND B.column_id = E.minor_id WHERE A.name = :1 AND D.table_name = :2 ORDER BY D.ordinal_position", table, table))
 	defer rows.Close()
 	for rows.Next() {
 		exists = true
 		var (
 			fieldType string
+			isDecimal bool
 			// 扫描的字段
 			column          string
 			dataType        string
@@ -59,8 +60,9 @@ func (g sqlServerGenerator) getTableInfo(table string) ([]fieldTplParam, string,
 			hasDefaultValue bool
 			comment         *string
 			incrementValue  *int
+			isPrimaryKey    bool
 		)
-		must(rows.Scan(&column, &incrementValue, &dataType, &isNotNull, &hasDefaultValue, &comment))
+		must(rows.Scan(&column, &incrementValue, &dataType, &isNotNull, &hasDefaultValue, &comment, &isPrimaryKey))
 		dataType = strings.ToLower(dataType)
 		if comment == nil {
 			comment = gdao.P("")
@@ -81,8 +83,11 @@ func (g sqlServerGenerator) getTableInfo(table string) ([]fieldTplParam, string,
 			fieldType = "*int64"
 		case "bit":
 			fieldType = "*bool"
-		case "decimal", "numeric", "money", "smallmoney", "float":
+		case "float":
 			fieldType = "*float64"
+		case "decimal", "numeric", "money", "smallmoney":
+			fieldType = "*float64"
+			isDecimal = true
 		case "real":
 			fieldType = "*float32"
 		case "date", "time", "datetime2", "datetimeoffset", "datetime", "smalldatetime":
@@ -97,12 +102,14 @@ func (g sqlServerGenerator) getTableInfo(table string) ([]fieldTplParam, string,
 			Column:            column,
 			FieldName:         fieldNameMapper.Convert(column),
 			FieldType:         fieldType,
+			IsPrimaryKey:      isPrimaryKey,
 			IsNotNull:         isNotNull,
 			HasDefaultValue:   hasDefaultValue,
 			Comment:           *comment,
 			Valid:             fieldType != "any",
 			IsAutoIncrement:   *incrementValue > 0,
 			AutoIncrementStep: *incrementValue,
+			isDecimal:         isDecimal,
 		}
 		fields = append(fields, f)
 	}
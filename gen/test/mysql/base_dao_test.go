@@ -17,6 +17,8 @@
 package mysql_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -147,6 +149,42 @@ func TestBaseDao_InsertBatch(t *testing.T) {
 	r.Equal(int32(9), *u2.Id)
 }
 
+func TestBaseDao_InsertBatch_Chunk(t *testing.T) {
+	r := require.New(t)
+	d, mock := dao.MockBaseDao[User](r, "user")
+	mock.ExpectBegin()
+	mock.ExpectPrepare(`INSERT INTO user\(name, phone, email\) VALUES\(\?, \?, \?\), \(\?, \?, \?\)`).
+		ExpectExec().WithArgs("abc", "12345", "email11", "def", "6789", "email22").WillReturnResult(sqlmock.NewResult(8, 2))
+	mock.ExpectPrepare(`INSERT INTO user\(name, phone, email\) VALUES\(\?, \?, \?\)`).
+		ExpectExec().WithArgs("ghi", "2468", "email33").WillReturnResult(sqlmock.NewResult(10, 1))
+	mock.ExpectCommit()
+
+	u := &User{
+		Name:  gdao.P("abc"),
+		Phone: gdao.P("12345"),
+		Email: gdao.P("email11"),
+	}
+	u2 := &User{
+		Name:  gdao.P("def"),
+		Phone: gdao.P("6789"),
+		Email: gdao.P("email22"),
+	}
+	u3 := &User{
+		Name:  gdao.P("ghi"),
+		Age:   gdao.P[int32](20),
+		Phone: gdao.P("2468"),
+		Email: gdao.P("email33"),
+	}
+	affected, err := d.InsertBatch().Entities(u, u2, u3).ChunkSize(2).Do()
+
+	r.NoError(err)
+	r.NoError(mock.ExpectationsWereMet())
+	r.Equal(int64(3), affected)
+	r.Equal(int32(8), *u.Id)
+	r.Equal(int32(9), *u2.Id)
+	r.Equal(int32(10), *u3.Id)
+}
+
 func TestBaseDao_Update(t *testing.T) {
 	r := require.New(t)
 	{
@@ -251,6 +289,34 @@ func TestBaseDao_UpdateBatch(t *testing.T) {
 	}
 }
 
+func TestBaseDao_UpdateBatch_Chunk(t *testing.T) {
+	r := require.New(t)
+	d, mock := dao.MockBaseDao[User](r, "user")
+	mock.ExpectBegin()
+	mock.ExpectExec(`SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare(`UPDATE user SET name = CASE id WHEN \? THEN \? WHEN \? THEN \? END WHERE id IN\(\?, \?\) AND status = \?`).
+		ExpectExec().WithArgs(1, "name1", 2, "name2", 1, 2, 1).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectPrepare(`UPDATE user SET name = CASE id WHEN \? THEN \? END WHERE id IN\(\?\) AND status = \?`).
+		ExpectExec().WithArgs(3, "name3", 3, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`RELEASE SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	var affected int64
+	err := gdao.Tx(nil, func(ctx context.Context) error {
+		var err error
+		affected, err = d.UpdateBatch().Ctx(ctx).Entities(
+			&User{Id: gdao.P[int32](1), Name: gdao.P("name1")},
+			&User{Id: gdao.P[int32](2), Name: gdao.P("name2")},
+			&User{Id: gdao.P[int32](3), Name: gdao.P("name3")},
+		).Where("id").Condition(dao.And().Eq("status", 1)).ChunkSize(2).Do()
+		return err
+	})
+
+	r.NoError(err)
+	r.NoError(mock.ExpectationsWereMet())
+	r.Equal(int64(3), affected)
+}
+
 func TestBaseDao_Delete(t *testing.T) {
 	r := require.New(t)
 	d, mock := dao.MockBaseDao[User](r, "user")
@@ -279,6 +345,387 @@ func TestBaseDao_Count(t *testing.T) {
 	r.Equal(int64(8), count.Int64())
 }
 
+func TestBaseDao_Strict(t *testing.T) {
+	r := require.New(t)
+	_, mock := dao.MockBaseDao[User](r, "user")
+	d := dao.BaseDaoBuilder[User]().Table("user").Strict(true).Build()
+	{
+		mock.ExpectPrepare(`SELECT id, name FROM user WHERE 1 = 1 ORDER BY create_at DESC`).
+			ExpectQuery().WillReturnRows(mock.NewRows([]string{"id", "name"}).AddRow(1, "lucy"))
+		list, err := d.List().Select("Id", "name").Condition(dao.And().Plain("1 = 1")).
+			OrderBy(dao.OrderBy().Desc("CreateAt")).Do()
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+		r.Len(list, 1)
+	}
+	{
+		var uce *gdao.UnknownColumnError
+		_, err := d.List().Select("id", "name; DROP TABLE user").Do()
+		r.True(errors.As(err, &uce))
+		r.Equal("name; DROP TABLE user", uce.Column)
+		_, err = d.List().OrderBy(dao.OrderBy().Asc("1")).Do()
+		r.True(errors.As(err, &uce))
+		r.Equal("1", uce.Column)
+		_, err = d.Count().Condition(dao.And().Eq("unknown", 1)).Do()
+		r.EqualError(err, `unknown column "unknown"`)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+}
+
+func TestBaseDao_Sharding(t *testing.T) {
+	r := require.New(t)
+	dao.MockBaseDao[User](r, "user")
+	db0, mock0, err := sqlmock.New()
+	r.NoError(err)
+	db1, mock1, err := sqlmock.New()
+	r.NoError(err)
+	shards := []gdao.Shard{{DB: db0, Suffix: "_0"}, {DB: db1, Suffix: "_1"}}
+	type shardKey struct{}
+	shardingOf := func(fanOut bool) *gdao.Sharding[User] {
+		return gdao.ShardingBuilder[User]().Shards(shards...).FanOut(fanOut).
+			ShardFunc(func(ctx context.Context, entity *User) (gdao.Shard, bool) {
+				if entity != nil && entity.Level != nil {
+					return shards[*entity.Level%2], true
+				}
+				if level, ok := ctx.Value(shardKey{}).(int32); ok {
+					return shards[level%2], true
+				}
+				return gdao.Shard{}, false
+			}).Build()
+	}
+	d := dao.BaseDaoBuilder[User]().Table("user").Sharding(shardingOf(true)).Build()
+	rejected := dao.BaseDaoBuilder[User]().Table("user").Sharding(shardingOf(false)).Build()
+	{
+		mock0.ExpectPrepare(`INSERT INTO user_0\(name, level\) VALUES\(\?, \?\), \(\?, \?\)`).
+			ExpectExec().WithArgs("a", 2, "c", 4).WillReturnResult(sqlmock.NewResult(1, 2))
+		mock1.ExpectPrepare(`INSERT INTO user_1\(name, level\) VALUES\(\?, \?\)`).
+			ExpectExec().WithArgs("b", 3).WillReturnResult(sqlmock.NewResult(1, 1))
+		affected, err := d.InsertBatch().Entities(
+			&User{Name: gdao.P("a"), Level: gdao.P[int32](2)},
+			&User{Name: gdao.P("b"), Level: gdao.P[int32](3)},
+			&User{Name: gdao.P("c"), Level: gdao.P[int32](4)},
+		).Do()
+		r.NoError(err)
+		r.Equal(int64(3), affected)
+	}
+	{
+		mock1.ExpectPrepare(`UPDATE user_1 SET name = \? WHERE id = \?`).
+			ExpectExec().WithArgs("b", 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		affected, err := d.Update().Ctx(context.WithValue(context.Background(), shardKey{}, int32(3))).
+			Entity(&User{Id: gdao.P[int32](2), Name: gdao.P("b")}).Where("id").Do()
+		r.NoError(err)
+		r.Equal(int64(1), affected)
+	}
+	{
+		mock0.ExpectPrepare(`SELECT id, name FROM user_0 WHERE status = \?`).
+			ExpectQuery().WithArgs(1).WillReturnRows(mock0.NewRows([]string{"id", "name"}).AddRow(1, "a"))
+		mock1.ExpectPrepare(`SELECT id, name FROM user_1 WHERE status = \?`).
+			ExpectQuery().WithArgs(1).WillReturnRows(mock1.NewRows([]string{"id", "name"}).AddRow(2, "b"))
+		list, err := d.List().Select("id", "name").Condition(dao.And().Eq("status", 1)).Do()
+		r.NoError(err)
+		r.Len(list, 2)
+		r.Equal("a", *list[0].Name)
+		r.Equal("b", *list[1].Name)
+
+		mock0.ExpectPrepare(`SELECT COUNT\(\*\) FROM user_0`).
+			ExpectQuery().WillReturnRows(mock0.NewRows([]string{"count"}).AddRow(3))
+		mock1.ExpectPrepare(`SELECT COUNT\(\*\) FROM user_1`).
+			ExpectQuery().WillReturnRows(mock1.NewRows([]string{"count"}).AddRow(4))
+		count, err := d.Count().Do()
+		r.NoError(err)
+		r.Equal(int64(7), count.Int64())
+
+		mock1.ExpectPrepare(`SELECT COUNT\(\*\) FROM user_1`).
+			ExpectQuery().WillReturnRows(mock1.NewRows([]string{"count"}).AddRow(4))
+		count, err = d.Count().Ctx(context.WithValue(context.Background(), shardKey{}, int32(1))).Do()
+		r.NoError(err)
+		r.Equal(int64(4), count.Int64())
+	}
+	{
+		mock0.ExpectPrepare(`SELECT id, name, age FROM user_0 ORDER BY age DESC LIMIT 3`).ExpectQuery().
+			WillReturnRows(mock0.NewRows([]string{"id", "name", "age"}).AddRow(1, "a", 40).AddRow(3, "c", 20).AddRow(5, "e", 10))
+		mock1.ExpectPrepare(`SELECT id, name, age FROM user_1 ORDER BY age DESC LIMIT 3`).ExpectQuery().
+			WillReturnRows(mock1.NewRows([]string{"id", "name", "age"}).AddRow(2, "b", 30).AddRow(4, "d", 25))
+		list, err := d.List().Select("id", "name").OrderBy(dao.OrderBy().Desc("age")).Page(dao.Page(1, 2)).Do()
+		r.NoError(err)
+		r.Len(list, 2)
+		r.Equal("b", *list[0].Name)
+		r.Equal("d", *list[1].Name)
+		r.Nil(list[0].Age)
+
+		mock0.ExpectPrepare(`SELECT id FROM user_0 LIMIT 2`).ExpectQuery().
+			WillReturnRows(mock0.NewRows([]string{"id"}).AddRow(1).AddRow(3))
+		mock1.ExpectPrepare(`SELECT id FROM user_1 LIMIT 2`).ExpectQuery().
+			WillReturnRows(mock1.NewRows([]string{"id"}).AddRow(2).AddRow(4))
+		list, err = d.List().Select("id").Page(dao.Page(0, 2)).Do()
+		r.NoError(err)
+		r.Len(list, 2)
+	}
+	{
+		mock0.ExpectPrepare(`INSERT INTO user_0\(name, level\) VALUES\(\?, \?\)`).
+			ExpectExec().WithArgs("a", 2).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock1.ExpectPrepare(`INSERT INTO user_1\(name, level\) VALUES\(\?, \?\)`).
+			ExpectExec().WithArgs("b", 3).WillReturnError(errors.New("shard 1 is down"))
+		affected, err := d.InsertBatch().Entities(
+			&User{Name: gdao.P("a"), Level: gdao.P[int32](2)},
+			&User{Name: gdao.P("b"), Level: gdao.P[int32](3)},
+		).Do()
+		r.ErrorContains(err, "shard 1 is down")
+		r.Equal(int64(1), affected)
+	}
+	{
+		_, err := d.Delete().Condition(dao.And().Eq("status", 1)).Do()
+		r.ErrorIs(err, gdao.ErrNoShardKey)
+		_, err = d.InsertBatch().Entities(&User{Name: gdao.P("a")}).Do()
+		r.ErrorIs(err, gdao.ErrNoShardKey)
+		_, err = rejected.List().Do()
+		r.ErrorIs(err, gdao.ErrNoShardKey)
+		r.PanicsWithValue(gdao.ErrNoShardKey, func() {
+			rejected.Count().Must(true).Do()
+		})
+	}
+	r.NoError(mock0.ExpectationsWereMet())
+	r.NoError(mock1.ExpectationsWereMet())
+}
+
+func TestBaseDao_Timeout(t *testing.T) {
+	r := require.New(t)
+	d, mock := dao.MockBaseDao[User](r, "user")
+	mock.ExpectPrepare(`SELECT id, name FROM user`).ExpectQuery().WillDelayFor(time.Second).
+		WillReturnRows(mock.NewRows([]string{"id", "name"}))
+	_, err := d.Get().Select("id", "name").Timeout(10 * time.Millisecond).Do()
+	r.ErrorIs(err, gdao.ErrTimeout)
+
+	mock.ExpectPrepare(`DELETE FROM user`).ExpectExec().WillDelayFor(time.Second).WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err = d.Delete().Timeout(10 * time.Millisecond).Do()
+	r.ErrorIs(err, gdao.ErrTimeout)
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func TestBaseDao_Cache(t *testing.T) {
+	r := require.New(t)
+	d, mock := dao.MockBaseDao[User](r, "user")
+	cache := gdao.NewLRUCache(100)
+	gdao.Config(gdao.Cfg{DefaultDB: d.Dao.DB(), Cache: cache})
+	mock.ExpectPrepare(`SELECT id, name FROM user WHERE id = \?`).ExpectQuery().WithArgs(1).
+		WillReturnRows(mock.NewRows([]string{"id", "name"}).AddRow(1, "foo"))
+	mock.ExpectPrepare(`SELECT COUNT\(\*\) FROM user`).ExpectQuery().WillReturnRows(mock.NewRows([]string{"COUNT(*)"}).AddRow(1))
+	for i := 0; i < 2; i++ {
+		u, err := d.Get().Select("id", "name").Condition(dao.And().Eq("id", 1)).Cache(time.Minute).Do()
+		r.NoError(err)
+		r.Equal("foo", *u.Name)
+		c, err := d.Count().Cache(time.Minute).Do()
+		r.NoError(err)
+		r.Equal(1, c.Int())
+	}
+	r.Equal(2, cache.Len())
+
+	mock.ExpectPrepare(`DELETE FROM user WHERE id = \?`).ExpectExec().WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err := d.Delete().Condition(dao.And().Eq("id", 1)).Do()
+	r.NoError(err)
+	r.Equal(0, cache.Len())
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func TestBaseDao_Fake(t *testing.T) {
+	r := require.New(t)
+	d, mock := dao.MockBaseDao[User](r, "user")
+	restore := d.Fake(
+		&User{Id: gdao.P[int32](1), Name: gdao.P("foo"), Age: gdao.P[int32](20), Status: gdao.P[int8](1)},
+		&User{Id: gdao.P[int32](2), Name: gdao.P("bar"), Age: gdao.P[int32](30), Status: gdao.P[int8](1)},
+	)
+
+	u := &User{Name: gdao.P("baz"), Age: gdao.P[int32](25)}
+	affected, err := d.Insert().Entity(u).Do()
+	r.NoError(err)
+	r.Equal(int64(1), affected)
+	r.Equal(int32(3), *u.Id)
+
+	list, err := d.List().Select("id", "Name").Condition(dao.Or().Ge("age", 25).Group(dao.And().Like("name", "fo"))).
+		OrderBy(dao.OrderBy().Desc("age")).Page(dao.Page(1, 10)).Do()
+	r.NoError(err)
+	r.Len(list, 2)
+	r.Equal("baz", *list[0].Name)
+	r.Nil(list[0].Age)
+	r.Equal("foo", *list[1].Name)
+
+	c, err := d.Count().Condition(dao.And().In("id", dao.InArgs(1, 3)).Not().IsNull("status")).Do()
+	r.NoError(err)
+	r.Equal(1, c.Int())
+
+	affected, err = d.Update().Entity(&User{Id: gdao.P[int32](3), Status: gdao.P[int8](2)}).Where("id").Do()
+	r.NoError(err)
+	r.Equal(int64(1), affected)
+	affected, err = d.UpdateBatch().Entities(&User{Id: gdao.P[int32](1), Level: gdao.P[int32](5)},
+		&User{Id: gdao.P[int32](2)}).Where("id").Do()
+	r.NoError(err)
+	r.Equal(int64(2), affected)
+	u, err = d.Get().Condition(dao.And().Eq("id", 2)).Do()
+	r.NoError(err)
+	r.Nil(u.Level)
+	u, err = d.Get().Condition(dao.And().Between("level", 1, 9)).Do()
+	r.NoError(err)
+	r.Equal("foo", *u.Name)
+
+	affected, err = d.Delete().Condition(dao.And().Eq("status", 2)).Do()
+	r.NoError(err)
+	r.Equal(int64(1), affected)
+	c, err = d.Count().Do()
+	r.NoError(err)
+	r.Equal(2, c.Int())
+
+	_, err = d.List().Condition(dao.And().Plain("age > 1")).Do()
+	r.EqualError(err, "plain condition is not supported by the fake: age > 1")
+	r.PanicsWithError(`unknown column "unknown"`, func() {
+		_, _ = d.List().Must(true).OrderBy(dao.OrderBy().Asc("unknown")).Do()
+	})
+	sql, _, err := d.Delete().Condition(dao.And().Eq("id", 1)).ToSql()
+	r.NoError(err)
+	r.Equal("DELETE FROM user WHERE id = ?", sql)
+
+	restore()
+	mock.ExpectPrepare(`SELECT COUNT\(\*\) FROM user`).ExpectQuery().WillReturnRows(mock.NewRows([]string{"COUNT(*)"}).AddRow(0))
+	c, err = d.Count().Do()
+	r.NoError(err)
+	r.Equal(0, c.Int())
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+type Stock struct {
+	Sku  *string `gdao:"column=sku;pk"`
+	Name *string `gdao:"column=name"`
+	Qty  *int32  `gdao:"column=qty"`
+}
+
+func TestBaseDao_FakeUpsert(t *testing.T) {
+	r := require.New(t)
+	d, _ := dao.MockBaseDao[Stock](r, "stock")
+	restore := d.Fake(&Stock{Sku: gdao.P("a"), Name: gdao.P("apple"), Qty: gdao.P[int32](1)})
+	defer restore()
+
+	_, err := d.Insert().Entity(&Stock{Sku: gdao.P("a"), Qty: gdao.P[int32](2)}).Do()
+	r.ErrorIs(err, gdao.ErrDuplicateKey)
+
+	affected, err := d.InsertBatch().InsertIgnore(true).
+		Entities(&Stock{Sku: gdao.P("a"), Qty: gdao.P[int32](2)}, &Stock{Sku: gdao.P("b"), Qty: gdao.P[int32](3)}).Do()
+	r.NoError(err)
+	r.Equal(int64(1), affected)
+	s, err := d.Get().Condition(dao.And().Eq("sku", "a")).Do()
+	r.NoError(err)
+	r.Equal(int32(1), *s.Qty)
+
+	affected, err = d.Insert().Entity(&Stock{Sku: gdao.P("a"), Qty: gdao.P[int32](2)}).
+		OnDuplicateKey(dao.OnDuplicateKey().SetValue("qty", 9)).Do()
+	r.NoError(err)
+	r.Equal(int64(2), affected)
+	s, err = d.Get().Condition(dao.And().Eq("sku", "a")).Do()
+	r.NoError(err)
+	r.Equal("apple", *s.Name)
+	r.Equal(int32(9), *s.Qty)
+
+	affected, err = d.InsertBatch().OnDuplicateKey(dao.OnDuplicateKey().SetValue("Qty", 9)).
+		Entities(&Stock{Sku: gdao.P("a")}, &Stock{Sku: gdao.P("c")}).Do()
+	r.NoError(err)
+	r.Equal(int64(1), affected)
+
+	_, err = d.InsertBatch().OnDuplicateKey(dao.OnDuplicateKey().SetValue("sku", "b")).
+		Entities(&Stock{Sku: gdao.P("a")}).Do()
+	r.ErrorIs(err, gdao.ErrDuplicateKey)
+
+	_, err = d.Insert().Entity(&Stock{Sku: gdao.P("a")}).
+		OnDuplicateKey(dao.OnDuplicateKey().SetPlain("qty", "qty + 1")).Do()
+	r.EqualError(err, "plain value is not supported by the fake: qty + 1")
+	c, err := d.Count().Do()
+	r.NoError(err)
+	r.Equal(3, c.Int())
+}
+
+type Member struct {
+	Id    *int32  `gdao:"column=id;auto"`
+	Name  *string `gdao:"column=name"`
+	Phone *string `gdao:"column=phone;sensitive"`
+}
+
+func TestBaseDao_Sensitive(t *testing.T) {
+	r := require.New(t)
+	d, mock := dao.MockBaseDao[Member](r, "member")
+	boom := errors.New("boom")
+	var e *gdao.Error
+	mock.ExpectPrepare(`UPDATE member SET phone = \? WHERE id = \?`).ExpectExec().WillReturnError(boom)
+	_, err := d.Update().Entity(&Member{Id: gdao.P[int32](1), Phone: gdao.P("13800000000")}).Where("id").Do()
+	r.ErrorAs(err, &e)
+	r.Equal([]any{"***", gdao.P[int32](1)}, e.Args)
+
+	mock.ExpectPrepare(`SELECT id, name, phone FROM member WHERE phone = \? OR phone IN\(\?, \?\) OR name = \?`).
+		ExpectQuery().WillReturnError(boom)
+	_, err = d.List().Condition(dao.Or().Eq("Phone", "13800000000").In("phone", dao.InArgs("1", "2")).Eq("name", "foo")).Do()
+	r.ErrorAs(err, &e)
+	r.Equal([]any{"***", "***", "***", "foo"}, e.Args)
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+type Article struct {
+	Id   *int32         `gdao:"column=id;auto"`
+	Tags []string       `gdao:"column=tags;json"`
+	Meta map[string]any `gdao:"column=meta;json"`
+}
+
+func TestBaseDao_JSON(t *testing.T) {
+	r := require.New(t)
+	d, mock := dao.MockBaseDao[Article](r, "article")
+	mock.ExpectPrepare(`INSERT INTO article\(tags, meta\) VALUES\(\?, \?\)`).ExpectExec().
+		WithArgs(`["a","b"]`, `{"n":1}`).WillReturnResult(sqlmock.NewResult(1, 1))
+	_, err := d.Insert().Entity(&Article{Tags: []string{"a", "b"}, Meta: map[string]any{"n": 1}}).Do()
+	r.NoError(err)
+
+	mock.ExpectPrepare(`SELECT id, tags, meta FROM article WHERE tags = \? AND meta IN\(\?, \?\)`).ExpectQuery().
+		WithArgs(`["a"]`, `{}`, `{"n":1}`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tags", "meta"}).AddRow(1, `["a"]`, `{"n":1}`))
+	list, err := d.List().Condition(dao.And().Eq("tags", []string{"a"}).In("meta", dao.InArgs[any](`{}`, map[string]any{"n": 1}))).Do()
+	r.NoError(err)
+	r.Equal([]*Article{{Id: gdao.P[int32](1), Tags: []string{"a"}, Meta: map[string]any{"n": float64(1)}}}, list)
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func TestBaseDao_ToSql(t *testing.T) {
+	r := require.New(t)
+	d, mock := dao.MockBaseDao[User](r, "user")
+	u := &User{Id: gdao.P[int32](1), Name: gdao.P("abc")}
+	u2 := &User{Id: gdao.P[int32](2), Name: gdao.P("def")}
+	cases := []struct {
+		toSql func() (string, []any, error)
+		sql   string
+		args  []any
+	}{
+		{d.List().Select("id").Condition(dao.And().Eq("status", 1)).Page(dao.Page(2, 10)).ToSql,
+			"SELECT id FROM user WHERE status = ? LIMIT 2, 10", []any{1}},
+		{d.Get().Condition(dao.And().Eq("id", 1)).ToSql,
+			"SELECT id, name, age, address, phone, email, status, level, create_at FROM user WHERE id = ?", []any{1}},
+		{d.Insert().Entity(u).ToSql,
+			"INSERT INTO user(name) VALUES(?)", []any{u.Name}},
+		{d.InsertBatch().Entities(u, u2).ChunkSize(1).ToSql,
+			"INSERT INTO user(name) VALUES(?), (?)", []any{u.Name, u2.Name}},
+		{d.Update().Entity(u).Where("id").ToSql,
+			"UPDATE user SET name = ? WHERE id = ?", []any{u.Name, u.Id}},
+		{d.UpdateBatch().Entities(u, u2).Where("id").ToSql,
+			"UPDATE user SET name = CASE id WHEN ? THEN ? WHEN ? THEN ? END WHERE id IN(?, ?)",
+			[]any{u.Id, u.Name, u2.Id, u2.Name, u.Id, u2.Id}},
+		{d.Delete().Condition(dao.And().Eq("id", 1)).ToSql,
+			"DELETE FROM user WHERE id = ?", []any{1}},
+		{d.Count().Condition(dao.And().Eq("status", 1)).ToSql,
+			"SELECT COUNT(*) FROM user WHERE status = ?", []any{1}},
+	}
+	for _, c := range cases {
+		sql, args, err := c.toSql()
+		r.NoError(err)
+		r.Equal(c.sql, sql)
+		r.Equal(c.args, args)
+	}
+	_, _, err := dao.BaseDaoBuilder[User]().Table("user").Strict(true).Build().List().Select("unknown").ToSql()
+	r.EqualError(err, `unknown column "unknown"`)
+	r.NoError(mock.ExpectationsWereMet())
+}
+
 func TestCond(t *testing.T) {
 	r := require.New(t)
 	{
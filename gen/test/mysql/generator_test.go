@@ -74,6 +74,7 @@ func TestMySql(t *testing.T) {
 					"other8":  gen.MappingConvert[pkg2.MyStruct3](),
 					"other9":  gen.MappingConvert[pkg3.MyStruct4](),
 					"other10": gen.MappingConvert[pkg4.MyStruct5](),
+					"json":    gen.MappingJSON[map[string]any](),
 				},
 			},
 			Ignores: gen.Ignores{
@@ -6,8 +6,11 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/jishaocong0910/gdao"
 )
@@ -23,6 +26,10 @@ type list[T any] struct {
 	logLevel gdao.LogLevel
 	// describe the sql in the log
 	desc string
+	// the timeout of the statement, it overrides the default timeout of the dao.
+	timeout time.Duration
+	// cache the result for the duration if gdao.Cfg.Cache is set.
+	cache time.Duration
 	// specify the columns which in the select column list, default is all columns.
 	sel []string
 	// conditions of the where clause，create by function And, Or and Not.
@@ -55,8 +62,18 @@ func (l *list[T]) Desc(desc string) *list[T] {
 	return l
 }
 
+func (l *list[T]) Timeout(timeout time.Duration) *list[T] {
+	l.timeout = timeout
+	return l
+}
+
+func (l *list[T]) Cache(ttl time.Duration) *list[T] {
+	l.cache = ttl
+	return l
+}
+
 func (l *list[T]) Select(sel ...string) *list[T] {
-	l.sel = mapColumns(l.dao.NameMap(), sel)
+	l.sel = sel
 	return l
 }
 
@@ -80,29 +97,97 @@ func (l *list[T]) ForUpdate(forUpdate bool) *list[T] {
 	return l
 }
 
+// ToSql returns the statement without executing it. The statement of the first shard is returned when fanning out.
+func (l *list[T]) ToSql() (string, []any, error) {
+	ctx, capture := gdao.CaptureSql(l.ctx)
+	cp := *l
+	cp.ctx = ctx
+	_, err := cp.Do()
+	return capture.Result(err)
+}
+
 func (l *list[T]) Do() ([]*T, error) {
-	_, list, err := l.dao.Query().Ctx(l.ctx).Must(l.must).LogLevel(l.logLevel).Desc(l.desc).BuildSql(func(b *gdao.DaoSqlBuilder[T]) {
-		b.Write("SELECT ").WriteColumns(l.sel...).Write(" FROM ").Write(l.dao.table)
-		if l.cond != nil && l.cond.len() > 0 {
-			b.Write(" WHERE ")
-			l.cond.write(l.dao.NameMap(), b.BaseSqlBuilder)
-		}
+	if f := l.dao.fakeOf(l.ctx); f != nil {
+		list, err := l.fakeDo(f)
+		return list, checkMust(l.must, err)
+	}
+	// each shard returns the rows up to the end of the page, they are merged, sorted and paged in memory
+	sel, paging := l.sel, l.paging
+	fanOut := l.dao.fansOut(l.ctx)
+	if fanOut && paging != nil {
+		paging = Page(0, paging.offset+paging.pageSize)
+	}
+	var extra []string
+	if fanOut && l.odrBy != nil && len(sel) > 0 {
+		sel, extra = l.odrBy.selectWith(sel)
+	}
+	list := make([]*T, 0)
+	_, err := l.dao.doShards(l.ctx, l.must, nil, true, func(ctx context.Context, _ []*T) (int64, error) {
+		_, shardList, err := l.dao.Query().Ctx(ctx).Must(l.must).LogLevel(l.logLevel).Desc(l.desc).Timeout(l.timeout).Cache(l.cache).BuildSql(func(b *gdao.DaoSqlBuilder[T]) {
+			b.Write("SELECT ").WriteColumns(l.dao.mapColumns(b.BaseSqlBuilder, sel)...).Write(" FROM ").Write(l.dao.tableOf(ctx))
+			if l.cond != nil && l.cond.len() > 0 {
+				b.Write(" WHERE ")
+				l.cond.write(l.dao, b.BaseSqlBuilder)
+			}
+			if l.odrBy != nil {
+				l.odrBy.write(l.dao, b.BaseSqlBuilder)
+			}
+			if paging != nil {
+				b.Write(" LIMIT ")
+				if paging.offset > 0 {
+					b.Write(strconv.FormatInt(int64(paging.offset), 10))
+					b.Write(", ")
+				}
+				b.Write(strconv.FormatInt(int64(paging.pageSize), 10))
+			}
+			if l.forUpdate {
+				b.Write(" FOR UPDATE")
+			}
+		}).Do()
+		list = append(list, shardList...)
+		return 0, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if fanOut {
 		if l.odrBy != nil {
-			l.odrBy.write(l.dao.NameMap(), b.BaseSqlBuilder)
+			if err = l.dao.sortRows(list, l.odrBy); err != nil {
+				return nil, checkMust(l.must, err)
+			}
 		}
-		if l.paging != nil {
-			b.Write(" LIMIT ")
-			if l.paging.offset > 0 {
-				b.Write(strconv.FormatInt(int64(l.paging.offset), 10))
-				b.Write(", ")
+		list = pageRows(list, l.paging)
+		for _, row := range list {
+			for _, column := range extra {
+				l.dao.ClearColumn(row, column)
 			}
-			b.Write(strconv.FormatInt(int64(l.paging.pageSize), 10))
 		}
-		if l.forUpdate {
-			b.Write(" FOR UPDATE")
+	}
+	return list, nil
+}
+
+// fakeDo queries the in-memory table like the statement.
+func (l *list[T]) fakeDo(f *gdao.FakeTable[T]) ([]*T, error) {
+	list, err := f.Find(l.dao.fakeMatch(f, l.cond))
+	if err != nil {
+		return nil, err
+	}
+	if l.odrBy != nil {
+		if err = l.dao.sortRows(list, l.odrBy); err != nil {
+			return nil, err
+		}
+	}
+	list = pageRows(list, l.paging)
+	if len(l.sel) > 0 {
+		sel, err := l.dao.columnsOf(l.sel)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range list {
+			f.Project(row, sel...)
 		}
-	}).Do()
-	return list, err
+	}
+	return list, nil
 }
 
 type get[T any] struct {
@@ -116,6 +201,10 @@ type get[T any] struct {
 	logLevel gdao.LogLevel
 	// describe the SQL in the log
 	desc string
+	// the timeout of the statement, it overrides the default timeout of the dao.
+	timeout time.Duration
+	// cache the result for the duration if gdao.Cfg.Cache is set.
+	cache time.Duration
 	// specify the columns which in the select column list, default is all columns.
 	sel []string
 	// conditions of the WHERE clause，create by function And, Or and Not.
@@ -148,8 +237,18 @@ func (g *get[T]) Desc(desc string) *get[T] { // coverage-ignore
 	return g
 }
 
+func (g *get[T]) Timeout(timeout time.Duration) *get[T] {
+	g.timeout = timeout
+	return g
+}
+
+func (g *get[T]) Cache(ttl time.Duration) *get[T] {
+	g.cache = ttl
+	return g
+}
+
 func (g *get[T]) Select(sel ...string) *get[T] {
-	g.sel = mapColumns(g.dao.NameMap(), sel)
+	g.sel = sel
 	return g
 }
 
@@ -173,8 +272,16 @@ func (g *get[T]) CheckOne(checkOne bool) *get[T] {
 	return g
 }
 
+func (g *get[T]) ToSql() (string, []any, error) {
+	ctx, capture := gdao.CaptureSql(g.ctx)
+	cp := *g
+	cp.ctx = ctx
+	_, err := cp.Do()
+	return capture.Result(err)
+}
+
 func (g *get[T]) Do() (*T, error) {
-	list, err := g.dao.List().Ctx(g.ctx).Must(g.must).LogLevel(g.logLevel).Desc(g.desc).
+	list, err := g.dao.List().Ctx(g.ctx).Must(g.must).LogLevel(g.logLevel).Desc(g.desc).Timeout(g.timeout).Cache(g.cache).
 		Select(g.sel...).Condition(g.cond).OrderBy(g.odrBy).ForUpdate(g.forUpdate).Do()
 	if len(list) == 0 { // coverage-ignore
 		return nil, err
@@ -199,6 +306,8 @@ type insert[T any] struct {
 	logLevel gdao.LogLevel
 	// describe the SQL in the log
 	desc string
+	// the timeout of the statement, it overrides the default timeout of the dao.
+	timeout time.Duration
 	// the non-nil fields will be saved, and the auto generated keys will be set in it.
 	entity *T
 	// if true, all fields will be saved, otherwise, save non-nil fields.
@@ -233,6 +342,11 @@ func (i *insert[T]) Desc(desc string) *insert[T] { // coverage-ignore
 	return i
 }
 
+func (i *insert[T]) Timeout(timeout time.Duration) *insert[T] { // coverage-ignore
+	i.timeout = timeout
+	return i
+}
+
 func (i *insert[T]) Entity(entity *T) *insert[T] {
 	i.entity = entity
 	return i
@@ -244,12 +358,12 @@ func (i *insert[T]) All(all bool) *insert[T] {
 }
 
 func (i *insert[T]) SetNull(setNull ...string) *insert[T] {
-	i.setNull = mapColumns(i.dao.NameMap(), setNull)
+	i.setNull = setNull
 	return i
 }
 
 func (i *insert[T]) Ignore(ignore ...string) *insert[T] {
-	i.ignore = mapColumns(i.dao.NameMap(), ignore)
+	i.ignore = ignore
 	return i
 }
 
@@ -263,8 +377,16 @@ func (i *insert[T]) OnDuplicateKey(onDuplKey *OnDuplKey) *insert[T] {
 	return i
 }
 
+func (i *insert[T]) ToSql() (string, []any, error) {
+	ctx, capture := gdao.CaptureSql(i.ctx)
+	cp := *i
+	cp.ctx = ctx
+	_, err := cp.Do()
+	return capture.Result(err)
+}
+
 func (i *insert[T]) Do() (int64, error) {
-	return i.dao.InsertBatch().Ctx(i.ctx).Must(i.must).LogLevel(i.logLevel).Desc(i.desc).Entities(i.entity).All(i.all).
+	return i.dao.InsertBatch().Ctx(i.ctx).Must(i.must).LogLevel(i.logLevel).Desc(i.desc).Timeout(i.timeout).Entities(i.entity).All(i.all).
 		SetNull(i.setNull...).Ignore(i.ignore...).InsertIgnore(i.insertIgnore).OnDuplicateKey(i.onDuplKey).Do()
 }
 
@@ -279,6 +401,8 @@ type insertBatch[T any] struct {
 	logLevel gdao.LogLevel
 	// describe the SQL in the log
 	desc string
+	// the timeout of the statement, it overrides the default timeout of the dao.
+	timeout time.Duration
 	// each element corresponds to a record to be saved, and the auto generated keys will be set in them.
 	entities []*T
 	// if true, all fields will be saved, otherwise, save non-nil fields.
@@ -291,6 +415,10 @@ type insertBatch[T any] struct {
 	insertIgnore bool
 	// INSERT ... ON DUPLICATE KEY UPDATE, create by function OnDuplicateKey
 	onDuplKey *OnDuplKey
+	// split the entities into chunks of the size, each chunk is executed by a statement.
+	chunkSize int
+	// if true, split the entities into chunks by the max number of parameters of the database.
+	autoChunk bool
 }
 
 func (ib *insertBatch[T]) Ctx(ctx context.Context) *insertBatch[T] {
@@ -313,6 +441,11 @@ func (ib *insertBatch[T]) Desc(desc string) *insertBatch[T] {
 	return ib
 }
 
+func (ib *insertBatch[T]) Timeout(timeout time.Duration) *insertBatch[T] { // coverage-ignore
+	ib.timeout = timeout
+	return ib
+}
+
 func (ib *insertBatch[T]) Entities(entities ...*T) *insertBatch[T] {
 	ib.entities = entities
 	return ib
@@ -324,12 +457,12 @@ func (ib *insertBatch[T]) All(all bool) *insertBatch[T] {
 }
 
 func (ib *insertBatch[T]) SetNull(setNull ...string) *insertBatch[T] {
-	ib.setNull = mapColumns(ib.dao.NameMap(), setNull)
+	ib.setNull = setNull
 	return ib
 }
 
 func (ib *insertBatch[T]) Ignore(ignore ...string) *insertBatch[T] {
-	ib.ignore = mapColumns(ib.dao.NameMap(), ignore)
+	ib.ignore = ignore
 	return ib
 }
 
@@ -343,59 +476,123 @@ func (ib *insertBatch[T]) OnDuplicateKey(onDuplKey *OnDuplKey) *insertBatch[T] {
 	return ib
 }
 
+func (ib *insertBatch[T]) ChunkSize(chunkSize int) *insertBatch[T] {
+	ib.chunkSize = chunkSize
+	return ib
+}
+
+func (ib *insertBatch[T]) AutoChunk(autoChunk bool) *insertBatch[T] {
+	ib.autoChunk = autoChunk
+	return ib
+}
+
+// ToSql returns the statement without executing it, the entities are not split into chunks.
+func (ib *insertBatch[T]) ToSql() (string, []any, error) {
+	ctx, capture := gdao.CaptureSql(ib.ctx)
+	cp := *ib
+	cp.ctx = ctx
+	cp.chunkSize, cp.autoChunk = 0, false
+	_, err := cp.Do()
+	return capture.Result(err)
+}
+
 func (ib *insertBatch[T]) Do() (int64, error) {
-	return ib.dao.Exec().Ctx(ib.ctx).Must(ib.must).LogLevel(ib.logLevel).Desc(ib.desc).Entities(ib.entities...).
-		LastInsertIdAs(gdao.LastInsertIdAs_.FIRST_ID).BuildSql(func(b *gdao.DaoSqlBuilder[T]) {
-		var setColumnNum, setNullColumnNum int
-		var allIgnore []string
-		allIgnore = append(allIgnore, ib.setNull...)
-		allIgnore = append(allIgnore, ib.ignore...)
-		allIgnore = append(allIgnore, b.AutoColumns()...)
-
-		b.Write("INSERT")
-		if ib.insertIgnore {
-			b.Write(" IGNORE")
-		}
-		b.Write(" INTO ").Write(ib.dao.table)
-		columns := b.Columns(!ib.all, allIgnore...)
-		b.Repeat(len(columns), b.SepFix("(", ", ", "", true), nil, func(_, i int) {
-			setColumnNum++
-			b.Write(columns[i])
-		})
-		if len(ib.setNull) > 0 {
-			if setColumnNum > 0 {
-				b.Write(", ")
-			}
-			b.Repeat(len(ib.setNull), b.Sep(", "), nil, func(_, i int) {
-				setNullColumnNum++
-				b.Write(ib.setNull[i])
-			})
+	if f := ib.dao.fakeOf(ib.ctx); f != nil {
+		affected, err := ib.fakeDo(f)
+		return affected, checkMust(ib.must, err)
+	}
+	var columns []string
+	chunkSize := ib.dao.chunkSize(ib.chunkSize, ib.autoChunk, len(ib.dao.NameMap()), func(b *gdao.BaseSqlBuilder) {
+		if ib.onDuplKey != nil {
+			ib.onDuplKey.write(ib.dao, b)
 		}
-		b.Write(")")
-		b.Write(" VALUES")
-		b.EachEntity(b.Sep(", "), func(_ int, entity *T) {
-			b.EachColumn(entity, b.SepFix("(", ", ", "", true), func(_ int, column string, value any) {
-				if value != nil {
-					b.Write("?", value)
-				} else {
-					b.Write("NULL")
+	})
+	return ib.dao.doShards(ib.ctx, ib.must, ib.entities, false, func(ctx context.Context, entities []*T) (int64, error) {
+		return ib.dao.doChunks(ctx, ib.must, entities, chunkSize, func(ctx context.Context, must bool, chunk int, entities []*T) (int64, error) {
+			return ib.dao.Exec().Ctx(ctx).Must(must).LogLevel(ib.logLevel).Desc(ib.desc).Timeout(ib.timeout).Entities(entities...).
+				LastInsertIdAs(gdao.LastInsertIdAs_.FIRST_ID).BuildSql(func(b *gdao.DaoSqlBuilder[T]) {
+				setNull := ib.dao.mapColumns(b.BaseSqlBuilder, ib.setNull)
+				ignore := ib.dao.mapColumns(b.BaseSqlBuilder, ib.ignore)
+				var setColumnNum, setNullColumnNum int
+				var allIgnore []string
+				allIgnore = append(allIgnore, setNull...)
+				allIgnore = append(allIgnore, ignore...)
+				allIgnore = append(allIgnore, b.AutoColumns()...)
+
+				b.Write("INSERT")
+				if ib.insertIgnore {
+					b.Write(" IGNORE")
 				}
-			}, columns...)
-			if len(ib.setNull) > 0 {
-				if setColumnNum > 0 {
-					b.Write(", ")
+				b.Write(" INTO ").Write(ib.dao.tableOf(ctx))
+				if chunk == 0 {
+					columns = b.Columns(!ib.all, allIgnore...)
 				}
-				b.Repeat(len(ib.setNull), b.Sep(", "), nil, func(_, i int) {
-					b.Write("NULL")
+				b.Repeat(len(columns), b.SepFix("(", ", ", "", true), nil, func(_, i int) {
+					setColumnNum++
+					b.Write(columns[i])
 				})
-			}
-			b.Write(")")
+				if len(setNull) > 0 {
+					if setColumnNum > 0 {
+						b.Write(", ")
+					}
+					b.Repeat(len(setNull), b.Sep(", "), nil, func(_, i int) {
+						setNullColumnNum++
+						b.Write(setNull[i])
+					})
+				}
+				b.Write(")")
+				b.Write(" VALUES")
+				b.EachEntity(b.Sep(", "), func(_ int, entity *T) {
+					b.EachColumn(entity, b.SepFix("(", ", ", "", true), func(_ int, column string, value any) {
+						if value != nil {
+							b.Write("?", value)
+						} else {
+							b.Write("NULL")
+						}
+					}, columns...)
+					if len(setNull) > 0 {
+						if setColumnNum > 0 {
+							b.Write(", ")
+						}
+						b.Repeat(len(setNull), b.Sep(", "), nil, func(_, i int) {
+							b.Write("NULL")
+						})
+					}
+					b.Write(")")
+				})
+
+				if ib.onDuplKey != nil {
+					ib.onDuplKey.write(ib.dao, b.BaseSqlBuilder)
+				}
+			}).Do()
 		})
+	})
+}
 
-		if ib.onDuplKey != nil {
-			ib.onDuplKey.write(ib.dao.NameMap(), b.BaseSqlBuilder)
+// fakeDo inserts the entities into the in-memory table, the columns are decided by the first entity like the
+// statement.
+func (ib *insertBatch[T]) fakeDo(f *gdao.FakeTable[T]) (int64, error) {
+	if len(ib.entities) == 0 {
+		return 0, nil
+	}
+	ignore, err := ib.dao.fakeIgnore(f, ib.entities[0], ib.all, slices.Concat(ib.setNull, ib.ignore))
+	if err != nil {
+		return 0, err
+	}
+	if ib.onDuplKey != nil {
+		update, err := ib.dao.fakeOnDuplKey(f, ib.onDuplKey)
+		if err != nil {
+			return 0, err
 		}
-	}).Do()
+		return f.Upsert(ib.entities, update, ignore...)
+	}
+	if ib.insertIgnore {
+		return f.Upsert(ib.entities, nil, ignore...)
+	}
+	if err = f.Insert(ib.entities, ignore...); err != nil {
+		return 0, err
+	}
+	return int64(len(ib.entities)), nil
 }
 
 type update[T any] struct {
@@ -409,6 +606,8 @@ type update[T any] struct {
 	logLevel gdao.LogLevel
 	// describe the SQL in the log
 	desc string
+	// the timeout of the statement, it overrides the default timeout of the dao.
+	timeout time.Duration
 	// uses to update values or the WHERE clause conditions.
 	entity *T
 	// if true, all fields will be updated, otherwise, update non-nil fields.
@@ -443,6 +642,11 @@ func (u *update[T]) Desc(desc string) *update[T] { // coverage-ignore
 	return u
 }
 
+func (u *update[T]) Timeout(timeout time.Duration) *update[T] { // coverage-ignore
+	u.timeout = timeout
+	return u
+}
+
 func (u *update[T]) Entity(entity *T) *update[T] {
 	u.entity = entity
 	return u
@@ -454,17 +658,17 @@ func (u *update[T]) All(all bool) *update[T] {
 }
 
 func (u *update[T]) SetNull(setNull ...string) *update[T] {
-	u.setNull = mapColumns(u.dao.NameMap(), setNull)
+	u.setNull = setNull
 	return u
 }
 
 func (u *update[T]) Ignore(ignore ...string) *update[T] {
-	u.ignore = mapColumns(u.dao.NameMap(), ignore)
+	u.ignore = ignore
 	return u
 }
 
 func (u *update[T]) Where(where ...string) *update[T] {
-	u.where = mapColumns(u.dao.NameMap(), where)
+	u.where = where
 	return u
 }
 
@@ -473,51 +677,94 @@ func (u *update[T]) Condition(cond Cond) *update[T] {
 	return u
 }
 
+func (u *update[T]) ToSql() (string, []any, error) {
+	ctx, capture := gdao.CaptureSql(u.ctx)
+	cp := *u
+	cp.ctx = ctx
+	_, err := cp.Do()
+	return capture.Result(err)
+}
+
 func (u *update[T]) Do() (int64, error) {
-	return u.dao.Exec().Ctx(u.ctx).Must(u.must).LogLevel(u.logLevel).Desc(u.desc).Entities(u.entity).BuildSql(func(b *gdao.DaoSqlBuilder[T]) {
-		var setColumnNum, setNullColumnNum int
-		var allIgnore []string
-		allIgnore = append(allIgnore, u.setNull...)
-		allIgnore = append(allIgnore, u.ignore...)
-		allIgnore = append(allIgnore, u.where...)
-
-		b.Write("UPDATE ").Write(u.dao.table).Write(" SET ")
-		columns := b.Columns(!u.all, allIgnore...)
-		b.EachColumn(b.Entity(), b.SepFix("", ", ", "", true), func(_ int, column string, value any) {
-			setColumnNum++
-			b.Write(column).Write(" = ")
-			if value != nil {
-				b.Write("?").SetArgs(value)
-			} else {
-				b.Write("NULL")
+	if f := u.dao.fakeOf(u.ctx); f != nil {
+		affected, err := u.fakeDo(f)
+		return affected, checkMust(u.must, err)
+	}
+	return u.dao.doShards(u.ctx, u.must, []*T{u.entity}, false, func(ctx context.Context, _ []*T) (int64, error) {
+		return u.dao.Exec().Ctx(ctx).Must(u.must).LogLevel(u.logLevel).Desc(u.desc).Timeout(u.timeout).Entities(u.entity).BuildSql(func(b *gdao.DaoSqlBuilder[T]) {
+			setNull := u.dao.mapColumns(b.BaseSqlBuilder, u.setNull)
+			ignore := u.dao.mapColumns(b.BaseSqlBuilder, u.ignore)
+			where := u.dao.mapColumns(b.BaseSqlBuilder, u.where)
+			var setColumnNum, setNullColumnNum int
+			var allIgnore []string
+			allIgnore = append(allIgnore, setNull...)
+			allIgnore = append(allIgnore, ignore...)
+			allIgnore = append(allIgnore, where...)
+
+			b.Write("UPDATE ").Write(u.dao.tableOf(ctx)).Write(" SET ")
+			columns := b.Columns(!u.all, allIgnore...)
+			b.EachColumn(b.Entity(), b.SepFix("", ", ", "", true), func(_ int, column string, value any) {
+				setColumnNum++
+				b.Write(column).Write(" = ")
+				if value != nil {
+					b.Write("?").SetArgs(value)
+				} else {
+					b.Write("NULL")
+				}
+			}, columns...)
+			if len(setNull) > 0 {
+				if setColumnNum > 0 {
+					b.Write(", ")
+				}
+				b.Repeat(len(setNull), b.Sep(", "), nil, func(_, i int) {
+					setNullColumnNum++
+					b.Write(setNull[i]).Write(" = NULL")
+				})
 			}
-		}, columns...)
-		if len(u.setNull) > 0 {
-			if setColumnNum > 0 {
-				b.Write(", ")
+
+			cond := And()
+			if len(where) > 0 {
+				b.EachColumn(b.Entity(), nil, func(_ int, column string, value any) {
+					if value == nil {
+						cond.IsNull(column)
+					} else {
+						cond.Eq(column, value)
+					}
+				}, where...)
 			}
-			b.Repeat(len(u.setNull), b.Sep(", "), nil, func(_, i int) {
-				setNullColumnNum++
-				b.Write(u.setNull[i]).Write(" = NULL")
-			})
-		}
+			cond.addCond(u.cond)
+			if cond.len() > 0 {
+				b.Write(" WHERE ")
+				cond.write(u.dao, b.BaseSqlBuilder)
+			}
+		}).Do()
+	})
+}
 
-		cond := And()
-		if len(u.where) > 0 {
-			b.EachColumn(b.Entity(), nil, func(_ int, column string, value any) {
-				if value == nil {
-					cond.IsNull(column)
-				} else {
-					cond.Eq(column, value)
-				}
-			}, u.where...)
-		}
-		cond.addCond(u.cond)
-		if cond.len() > 0 {
-			b.Write(" WHERE ")
-			cond.write(u.dao.NameMap(), b.BaseSqlBuilder)
+// fakeDo updates the rows of the in-memory table like the statement.
+func (u *update[T]) fakeDo(f *gdao.FakeTable[T]) (int64, error) {
+	where, err := u.dao.columnsOf(u.where)
+	if err != nil {
+		return 0, err
+	}
+	setNull, err := u.dao.columnsOf(u.setNull)
+	if err != nil {
+		return 0, err
+	}
+	ignore, err := u.dao.columnsOf(u.ignore)
+	if err != nil {
+		return 0, err
+	}
+	cond := And()
+	for _, column := range where {
+		if value := f.Value(u.entity, column); value == nil {
+			cond.IsNull(column)
+		} else {
+			cond.Eq(column, value)
 		}
-	}).Do()
+	}
+	cond.addCond(u.cond)
+	return f.Update(u.dao.fakeMatch(f, cond), u.entity, u.all, setNull, append(ignore, where...)...)
 }
 
 type updateBatch[T any] struct {
@@ -531,6 +778,8 @@ type updateBatch[T any] struct {
 	logLevel gdao.LogLevel
 	// describe the SQL in the log
 	desc string
+	// the timeout of the statement, it overrides the default timeout of the dao.
+	timeout time.Duration
 	// each element corresponds to a record to be updated.
 	entities []*T
 	// if true, all fields will be updated, otherwise, update non-nil fields.
@@ -543,6 +792,10 @@ type updateBatch[T any] struct {
 	where string
 	// conditions of the WHERE clause，create by function And, Or and Not..
 	cond Cond
+	// split the entities into chunks of the size, each chunk is executed by a statement.
+	chunkSize int
+	// if true, split the entities into chunks by the max number of parameters of the database.
+	autoChunk bool
 }
 
 func (u *updateBatch[T]) Ctx(ctx context.Context) *updateBatch[T] { // coverage-ignore
@@ -565,6 +818,11 @@ func (u *updateBatch[T]) Desc(desc string) *updateBatch[T] { // coverage-ignore
 	return u
 }
 
+func (u *updateBatch[T]) Timeout(timeout time.Duration) *updateBatch[T] { // coverage-ignore
+	u.timeout = timeout
+	return u
+}
+
 func (u *updateBatch[T]) Entities(entities ...*T) *updateBatch[T] {
 	u.entities = entities
 	return u
@@ -576,17 +834,17 @@ func (u *updateBatch[T]) All(all bool) *updateBatch[T] {
 }
 
 func (u *updateBatch[T]) SetNull(setNull ...string) *updateBatch[T] {
-	u.setNull = mapColumns(u.dao.NameMap(), setNull)
+	u.setNull = setNull
 	return u
 }
 
 func (u *updateBatch[T]) Ignore(ignore ...string) *updateBatch[T] {
-	u.ignore = mapColumns(u.dao.NameMap(), ignore)
+	u.ignore = ignore
 	return u
 }
 
 func (u *updateBatch[T]) Where(where string) *updateBatch[T] {
-	u.where = mapColumn(u.dao.NameMap(), where)
+	u.where = where
 	return u
 }
 
@@ -595,49 +853,119 @@ func (u *updateBatch[T]) Condition(cond Cond) *updateBatch[T] {
 	return u
 }
 
+func (u *updateBatch[T]) ChunkSize(chunkSize int) *updateBatch[T] {
+	u.chunkSize = chunkSize
+	return u
+}
+
+func (u *updateBatch[T]) AutoChunk(autoChunk bool) *updateBatch[T] {
+	u.autoChunk = autoChunk
+	return u
+}
+
+func (u *updateBatch[T]) ToSql() (string, []any, error) {
+	ctx, capture := gdao.CaptureSql(u.ctx)
+	cp := *u
+	cp.ctx = ctx
+	cp.chunkSize, cp.autoChunk = 0, false
+	_, err := cp.Do()
+	return capture.Result(err)
+}
+
 func (u *updateBatch[T]) Do() (int64, error) {
-	return u.dao.Exec().Ctx(u.ctx).Must(u.must).LogLevel(u.logLevel).Desc(u.desc).Entities(u.entities...).BuildSql(func(b *gdao.DaoSqlBuilder[T]) {
-		var setColumnNum, setNullColumnNum int
-		var allIgnore []string
-		allIgnore = append(allIgnore, u.setNull...)
-		allIgnore = append(allIgnore, u.ignore...)
-		allIgnore = append(allIgnore, u.where)
-
-		b.Write("UPDATE ").Write(u.dao.table).Write(" SET ")
-		columns := b.Columns(!u.all, allIgnore...)
-		b.EachColumn(b.Entity(), b.SepFix("", ", ", "", true), func(_ int, column string, value any) {
-			setColumnNum++
-			b.Write(column).Write(" = CASE ").Write(u.where)
-			b.EachEntity(nil, func(_ int, entity *T) {
-				b.Write(" WHEN ").Write("?", b.ColumnValue(entity, u.where)).Write(" THEN ")
-				if value != nil {
-					b.Write("?").SetArgs(b.ColumnValue(entity, column))
-				} else {
-					b.Write("NULL")
-				}
-			})
-			b.Write(" END")
-		}, columns...)
-		if len(u.setNull) > 0 {
-			if setColumnNum > 0 {
-				b.Write(", ")
-			}
-			b.Repeat(len(u.setNull), b.Sep(", "), nil, func(_, i int) {
-				setNullColumnNum++
-				b.Write(u.setNull[i]).Write(" = NULL")
-			})
+	if f := u.dao.fakeOf(u.ctx); f != nil {
+		affected, err := u.fakeDo(f)
+		return affected, checkMust(u.must, err)
+	}
+	var first *T
+	var columns []string
+	chunkSize := u.dao.chunkSize(u.chunkSize, u.autoChunk, 2*len(u.dao.NameMap())+1, func(b *gdao.BaseSqlBuilder) {
+		if u.cond != nil {
+			u.cond.write(u.dao, b)
 		}
+	})
+	return u.dao.doShards(u.ctx, u.must, u.entities, false, func(ctx context.Context, entities []*T) (int64, error) {
+		return u.dao.doChunks(ctx, u.must, entities, chunkSize, func(ctx context.Context, must bool, chunk int, entities []*T) (int64, error) {
+			return u.dao.Exec().Ctx(ctx).Must(must).LogLevel(u.logLevel).Desc(u.desc).Timeout(u.timeout).Entities(entities...).BuildSql(func(b *gdao.DaoSqlBuilder[T]) {
+				setNull := u.dao.mapColumns(b.BaseSqlBuilder, u.setNull)
+				ignore := u.dao.mapColumns(b.BaseSqlBuilder, u.ignore)
+				where := u.dao.mapColumn(b.BaseSqlBuilder, u.where)
+				var setColumnNum, setNullColumnNum int
+				var allIgnore []string
+				allIgnore = append(allIgnore, setNull...)
+				allIgnore = append(allIgnore, ignore...)
+				allIgnore = append(allIgnore, where)
+
+				b.Write("UPDATE ").Write(u.dao.tableOf(ctx)).Write(" SET ")
+				if chunk == 0 {
+					first = b.Entity()
+					columns = b.Columns(!u.all, allIgnore...)
+				}
+				b.EachColumn(first, b.SepFix("", ", ", "", true), func(_ int, column string, value any) {
+					setColumnNum++
+					b.Write(column).Write(" = CASE ").Write(where)
+					b.EachEntity(nil, func(_ int, entity *T) {
+						b.Write(" WHEN ").Write("?", b.ColumnValue(entity, where)).Write(" THEN ")
+						if value != nil {
+							b.Write("?").SetArgs(b.ColumnValue(entity, column))
+						} else {
+							b.Write("NULL")
+						}
+					})
+					b.Write(" END")
+				}, columns...)
+				if len(setNull) > 0 {
+					if setColumnNum > 0 {
+						b.Write(", ")
+					}
+					b.Repeat(len(setNull), b.Sep(", "), nil, func(_, i int) {
+						setNullColumnNum++
+						b.Write(setNull[i]).Write(" = NULL")
+					})
+				}
 
-		b.Write(" WHERE ")
-		cond := And()
-		whereColumnValues := make([]any, 0, len(u.entities))
-		b.EachEntity(nil, func(_ int, entity *T) {
-			whereColumnValues = append(whereColumnValues, b.ColumnValue(entity, u.where))
+				b.Write(" WHERE ")
+				cond := And()
+				whereColumnValues := make([]any, 0, len(entities))
+				b.EachEntity(nil, func(_ int, entity *T) {
+					whereColumnValues = append(whereColumnValues, b.ColumnValue(entity, where))
+				})
+				cond.In(where, InArgs(whereColumnValues...))
+				cond.addCond(u.cond)
+				cond.write(u.dao, b.BaseSqlBuilder)
+			}).Do()
 		})
-		cond.In(u.where, InArgs(whereColumnValues...))
-		cond.addCond(u.cond)
-		cond.write(u.dao.NameMap(), b.BaseSqlBuilder)
-	}).Do()
+	})
+}
+
+// fakeDo updates the rows of the in-memory table entity by entity, the columns are decided by the first entity like
+// the statement.
+func (u *updateBatch[T]) fakeDo(f *gdao.FakeTable[T]) (int64, error) {
+	if len(u.entities) == 0 {
+		return 0, nil
+	}
+	where, err := u.dao.columnsOf([]string{u.where})
+	if err != nil {
+		return 0, err
+	}
+	setNull, err := u.dao.columnsOf(u.setNull)
+	if err != nil {
+		return 0, err
+	}
+	ignore, err := u.dao.fakeIgnore(f, u.entities[0], u.all, u.ignore)
+	if err != nil {
+		return 0, err
+	}
+	var affected int64
+	for _, entity := range u.entities {
+		cond := And().Eq(where[0], f.Value(entity, where[0])).addCond(u.cond)
+		n, err := f.Update(u.dao.fakeMatch(f, cond), entity, true, setNull, append(ignore, where[0])...)
+		if err != nil {
+			return 0, err
+		}
+		affected += n
+	}
+	return affected, nil
 }
 
 type delete[T any] struct {
@@ -651,6 +979,8 @@ type delete[T any] struct {
 	logLevel gdao.LogLevel
 	// describe the SQL in the log
 	desc string
+	// the timeout of the statement, it overrides the default timeout of the dao.
+	timeout time.Duration
 	// conditions of the WHERE clause，create by function And, Or and Not.
 	cond Cond
 }
@@ -675,19 +1005,38 @@ func (d *delete[T]) Desc(desc string) *delete[T] { // coverage-ignore
 	return d
 }
 
+func (d *delete[T]) Timeout(timeout time.Duration) *delete[T] {
+	d.timeout = timeout
+	return d
+}
+
 func (d *delete[T]) Condition(cond Cond) *delete[T] {
 	d.cond = cond
 	return d
 }
 
+func (d *delete[T]) ToSql() (string, []any, error) {
+	ctx, capture := gdao.CaptureSql(d.ctx)
+	cp := *d
+	cp.ctx = ctx
+	_, err := cp.Do()
+	return capture.Result(err)
+}
+
 func (d *delete[T]) Do() (int64, error) {
-	return d.dao.Exec().Ctx(d.ctx).Must(d.must).LogLevel(d.logLevel).Desc(d.desc).BuildSql(func(b *gdao.DaoSqlBuilder[T]) {
-		b.Write("DELETE FROM ").Write(d.dao.table)
-		if d.cond != nil && d.cond.len() > 0 {
-			b.Write(" WHERE ")
-			d.cond.write(d.dao.NameMap(), b.BaseSqlBuilder)
-		}
-	}).Do()
+	if f := d.dao.fakeOf(d.ctx); f != nil {
+		affected, err := f.Delete(d.dao.fakeMatch(f, d.cond))
+		return affected, checkMust(d.must, err)
+	}
+	return d.dao.doShards(d.ctx, d.must, nil, false, func(ctx context.Context, _ []*T) (int64, error) {
+		return d.dao.Exec().Ctx(ctx).Must(d.must).LogLevel(d.logLevel).Desc(d.desc).Timeout(d.timeout).BuildSql(func(b *gdao.DaoSqlBuilder[T]) {
+			b.Write("DELETE FROM ").Write(d.dao.tableOf(ctx))
+			if d.cond != nil && d.cond.len() > 0 {
+				b.Write(" WHERE ")
+				d.cond.write(d.dao, b.BaseSqlBuilder)
+			}
+		}).Do()
+	})
 }
 
 type count[T any] struct {
@@ -701,6 +1050,10 @@ type count[T any] struct {
 	logLevel gdao.LogLevel
 	// describe the SQL in the log
 	desc string
+	// the timeout of the statement, it overrides the default timeout of the dao.
+	timeout time.Duration
+	// cache the result for the duration if gdao.Cfg.Cache is set.
+	cache time.Duration
 	// conditions of the WHERE clause，create by function And, Or and Not.
 	cond Cond
 }
@@ -725,25 +1078,70 @@ func (c *count[T]) Desc(desc string) *count[T] { // coverage-ignore
 	return c
 }
 
+func (c *count[T]) Timeout(timeout time.Duration) *count[T] { // coverage-ignore
+	c.timeout = timeout
+	return c
+}
+
+func (c *count[T]) Cache(ttl time.Duration) *count[T] {
+	c.cache = ttl
+	return c
+}
+
 func (c *count[T]) Condition(cond Cond) *count[T] {
 	c.cond = cond
 	return c
 }
 
+func (c *count[T]) ToSql() (string, []any, error) {
+	ctx, capture := gdao.CaptureSql(c.ctx)
+	cp := *c
+	cp.ctx = ctx
+	_, err := cp.Do()
+	return capture.Result(err)
+}
+
 func (c *count[T]) Do() (*gdao.Count, error) {
-	return c.dao.CountDao.Count().Ctx(c.ctx).Must(c.must).LogLevel(c.logLevel).Desc(c.desc).BuildSql(func(b *gdao.CountBuilder) {
-		b.Write("SELECT COUNT(*) FROM ").Write(c.dao.table)
-		if c.cond != nil && c.cond.len() > 0 {
-			b.Write(" WHERE ")
-			c.cond.write(c.dao.NameMap(), b.BaseSqlBuilder)
+	if f := c.dao.fakeOf(c.ctx); f != nil {
+		list, err := f.Find(c.dao.fakeMatch(f, c.cond))
+		if err = checkMust(c.must, err); err != nil {
+			return nil, err
 		}
-	}).Do()
+		total := int64(len(list))
+		return &gdao.Count{Value: &total}, nil
+	}
+	var total *gdao.Count
+	_, err := c.dao.doShards(c.ctx, c.must, nil, true, func(ctx context.Context, _ []*T) (int64, error) {
+		count, err := c.dao.CountDao.Count().Ctx(ctx).Must(c.must).LogLevel(c.logLevel).Desc(c.desc).Timeout(c.timeout).Cache(c.cache).BuildSql(func(b *gdao.CountBuilder) {
+			b.Write("SELECT COUNT(*) FROM ").Write(c.dao.tableOf(ctx))
+			if c.cond != nil && c.cond.len() > 0 {
+				b.Write(" WHERE ")
+				c.cond.write(c.dao, b.BaseSqlBuilder)
+			}
+		}).Do()
+		if err == nil && count != nil {
+			if total == nil {
+				total = count
+			} else {
+				sum := total.Int64() + count.Int64()
+				total = &gdao.Count{Value: &sum}
+			}
+		}
+		return 0, err
+	})
+	return total, err
 }
 
+// maxParams is the max number of parameters of a statement, used by automatic chunking.
+const maxParams = 65535
+
 type baseDao[T any] struct {
 	*gdao.Dao[T]
 	*gdao.CountDao
-	table string
+	table    string
+	strict   bool
+	sharding *gdao.Sharding[T]
+	fake     atomic.Pointer[gdao.FakeTable[T]]
 }
 
 func (d *baseDao[T]) List() *list[T] {
@@ -778,11 +1176,290 @@ func (d *baseDao[T]) Count() *count[T] {
 	return &count[T]{dao: d}
 }
 
+func (d *baseDao[T]) mapColumn(b *gdao.BaseSqlBuilder, column string) string {
+	if c, ok := d.ColumnOf(column); ok {
+		return c
+	}
+	if d.strict {
+		b.SetError(&gdao.UnknownColumnError{Column: column})
+	}
+	return column
+}
+
+func (d *baseDao[T]) mapArg(column string, arg any) any {
+	if d.IsJSON(column) {
+		arg = d.JSONArg(arg)
+	}
+	if d.IsSensitive(column) {
+		return gdao.SensitiveArg(column, arg)
+	}
+	return arg
+}
+
+func (d *baseDao[T]) mapColumns(b *gdao.BaseSqlBuilder, columns []string) []string {
+	var target []string
+	for _, column := range columns {
+		target = append(target, d.mapColumn(b, column))
+	}
+	return target
+}
+
+// tableOf returns the table name of the shard of ctx.
+func (d *baseDao[T]) tableOf(ctx context.Context) string {
+	if shard, ok := gdao.ShardOf(ctx); ok {
+		return d.table + shard.Suffix
+	}
+	return d.table
+}
+
+// doShards executes do on the shard of each group of entities, the statements without entities are executed on the
+// shard of ctx, or on all shards if fanOut is true and the Sharding allows. It returns the sum of the affected rows.
+func (d *baseDao[T]) doShards(ctx context.Context, must bool, entities []*T, fanOut bool,
+	do func(ctx context.Context, entities []*T) (int64, error)) (int64, error) {
+	if d.sharding == nil {
+		return do(ctx, entities)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var shards []gdao.Shard
+	groups := make(map[gdao.Shard][]*T)
+	if len(entities) == 0 {
+		if shard, ok := d.sharding.Route(ctx, nil); ok {
+			shards = append(shards, shard)
+		} else if fanOut && d.sharding.FanOut() {
+			shards = d.sharding.Shards()
+		} else {
+			if must {
+				panic(gdao.ErrNoShardKey)
+			}
+			return 0, gdao.ErrNoShardKey
+		}
+	} else {
+		for _, entity := range entities {
+			shard, ok := d.sharding.Route(ctx, entity)
+			if !ok {
+				if must {
+					panic(gdao.ErrNoShardKey)
+				}
+				return 0, gdao.ErrNoShardKey
+			}
+			if _, exists := groups[shard]; !exists {
+				shards = append(shards, shard)
+			}
+			groups[shard] = append(groups[shard], entity)
+		}
+	}
+	var affected int64
+	for _, shard := range shards {
+		n, err := do(gdao.WithShard(ctx, shard), groups[shard])
+		affected += n
+		if err != nil {
+			return affected, err
+		}
+	}
+	return affected, nil
+}
+
+// fansOut reports whether the statements without entities are executed on more than one shard in ctx.
+func (d *baseDao[T]) fansOut(ctx context.Context) bool {
+	if d.sharding == nil || !d.sharding.FanOut() || len(d.sharding.Shards()) < 2 {
+		return false
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, routed := d.sharding.Route(ctx, nil)
+	return !routed
+}
+
+// columnsOf maps the names to the columns, an error is returned for the unknown names.
+func (d *baseDao[T]) columnsOf(names []string) ([]string, error) {
+	columns := make([]string, 0, len(names))
+	for _, name := range names {
+		column, ok := d.ColumnOf(name)
+		if !ok {
+			return nil, &gdao.UnknownColumnError{Column: name}
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// checkMust panics if must is true and err is not nil, otherwise err is returned.
+func checkMust(must bool, err error) error {
+	if must && err != nil {
+		panic(err)
+	}
+	return err
+}
+
+// sortRows sorts the rows in memory like the ORDER BY clause, the equal rows keep their order.
+func (d *baseDao[T]) sortRows(rows []*T, odrBy *OdrBy) error {
+	columns := make([]string, 0, len(odrBy.items))
+	for _, item := range odrBy.items {
+		columns = append(columns, item.column)
+	}
+	columns, err := d.columnsOf(columns)
+	if err != nil {
+		return err
+	}
+	slices.SortStableFunc(rows, func(a, b *T) int {
+		for i, item := range odrBy.items {
+			c, e := gdao.CompareValues(d.ColumnValue(a, columns[i]), d.ColumnValue(b, columns[i]))
+			if e != nil {
+				err = e
+			}
+			if item.seq == desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return err
+}
+
+// pageRows returns the rows of the page, all rows if paging is nil.
+func pageRows[T any](rows []*T, paging *Paging) []*T {
+	if paging == nil {
+		return rows
+	}
+	rows = rows[min(paging.offset, len(rows)):]
+	return rows[:min(paging.pageSize, len(rows))]
+}
+
+// chunkSize returns the number of entities of each chunk, 0 means not to chunk. In automatic mode, the size is
+// calculated by the max number of parameters, the parameters written by others are reserved.
+func (d *baseDao[T]) chunkSize(size int, auto bool, paramsPerEntity int, others func(b *gdao.BaseSqlBuilder)) int {
+	if size > 0 || !auto {
+		return size
+	}
+	reserved := 0
+	if others != nil {
+		b := newTempSqlBuilder()
+		others(b.BaseSqlBuilder)
+		reserved = len(b.Args())
+	}
+	return max((maxParams-reserved)/max(paramsPerEntity, 1), 1)
+}
+
+// doChunks executes the entities chunk by chunk in a transaction, the ambient transaction in ctx is used if exists.
+func (d *baseDao[T]) doChunks(ctx context.Context, must bool, entities []*T, chunkSize int,
+	do func(ctx context.Context, must bool, chunk int, entities []*T) (int64, error)) (int64, error) {
+	if chunkSize <= 0 || len(entities) <= chunkSize {
+		return do(ctx, must, 0, entities)
+	}
+	db := d.Dao.DB()
+	if shard, ok := gdao.ShardOf(ctx); ok && shard.DB != nil {
+		db = shard.DB
+	}
+	opts := []gdao.TxOption{gdao.WithDefaultTx(db, nil)}
+	if must {
+		opts = append(opts, gdao.WithMust())
+	}
+	var affected int64
+	err := gdao.Tx(ctx, func(ctx context.Context) error {
+		for chunk := 0; chunk*chunkSize < len(entities); chunk++ {
+			n, err := do(ctx, false, chunk, entities[chunk*chunkSize:min((chunk+1)*chunkSize, len(entities))])
+			if err != nil {
+				return err
+			}
+			affected += n
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// Fake makes the dao execute the statements on an in-memory table of rows instead of the database, it is used by the
+// unit tests of the services. Call restore to use the database again.
+func (d *baseDao[T]) Fake(rows ...*T) (restore func()) {
+	d.fake.Store(gdao.NewFakeTable(d.Dao, rows...))
+	return func() {
+		d.fake.Store(nil)
+	}
+}
+
+// fakeOf returns the in-memory table if the dao is faked, the statements of ToSql are always built.
+func (d *baseDao[T]) fakeOf(ctx context.Context) *gdao.FakeTable[T] {
+	if gdao.SqlCaptureOf(ctx) != nil {
+		return nil
+	}
+	return d.fake.Load()
+}
+
+// fakeIgnore returns the columns of names, and the nil columns of first if all is false.
+func (d *baseDao[T]) fakeIgnore(f *gdao.FakeTable[T], first *T, all bool, names []string) ([]string, error) {
+	ignore, err := d.columnsOf(names)
+	if err != nil {
+		return nil, err
+	}
+	if !all {
+		for _, column := range d.NameMap() {
+			if f.Value(first, column) == nil {
+				ignore = append(ignore, column)
+			}
+		}
+	}
+	return ignore, nil
+}
+
+// fakeOnDuplKey returns a function applying the assignments of o to a row of the in-memory table, the plain values
+// are not supported by the fake.
+func (d *baseDao[T]) fakeOnDuplKey(f *gdao.FakeTable[T], o *OnDuplKey) (func(row *T) error, error) {
+	names := make([]string, 0, len(o.items))
+	for _, item := range o.items {
+		if item.plain {
+			return nil, errors.New("plain value is not supported by the fake: " + item.value.(string))
+		}
+		names = append(names, item.column)
+	}
+	columns, err := d.columnsOf(names)
+	if err != nil {
+		return nil, err
+	}
+	return func(row *T) error {
+		for i, item := range o.items {
+			if err := f.Set(row, columns[i], item.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+// fakeMatch returns a function reporting whether a row of the in-memory table matches cond.
+func (d *baseDao[T]) fakeMatch(f *gdao.FakeTable[T], cond Cond) func(row *T) (bool, error) {
+	return func(row *T) (bool, error) {
+		if cond == nil || cond.len() == 0 {
+			return true, nil
+		}
+		return cond.match(func(name string) (any, error) {
+			columns, err := d.columnsOf([]string{name})
+			if err != nil {
+				return nil, err
+			}
+			return f.Value(row, columns[0]), nil
+		})
+	}
+}
+
 type baseDaoBuilder[T any] struct {
 	db                *sql.DB
+	resolver          gdao.DBResolver
 	allowInvalidField bool
 	columnMapper      *gdao.NameMapper
 	table             string
+	strict            bool
+	sharding          *gdao.Sharding[T]
+	timeout           time.Duration
+	cfg               gdao.CfgOverride
 }
 
 func (b *baseDaoBuilder[T]) DB(db *sql.DB) *baseDaoBuilder[T] { // coverage-ignore
@@ -790,6 +1467,11 @@ func (b *baseDaoBuilder[T]) DB(db *sql.DB) *baseDaoBuilder[T] { // coverage-igno
 	return b
 }
 
+func (b *baseDaoBuilder[T]) Resolver(resolver gdao.DBResolver) *baseDaoBuilder[T] { // coverage-ignore
+	b.resolver = resolver
+	return b
+}
+
 func (b *baseDaoBuilder[T]) AllowInvalidField(allowInvalidField bool) *baseDaoBuilder[T] { // coverage-ignore
 	b.allowInvalidField = allowInvalidField
 	return b
@@ -805,13 +1487,35 @@ func (b *baseDaoBuilder[T]) Table(table string) *baseDaoBuilder[T] {
 	return b
 }
 
+func (b *baseDaoBuilder[T]) Strict(strict bool) *baseDaoBuilder[T] {
+	b.strict = strict
+	return b
+}
+
+// Sharding routes the statements to the shards, the table name is appended with the suffix of the shard.
+func (b *baseDaoBuilder[T]) Sharding(sharding *gdao.Sharding[T]) *baseDaoBuilder[T] {
+	b.sharding = sharding
+	return b
+}
+
+func (b *baseDaoBuilder[T]) Timeout(timeout time.Duration) *baseDaoBuilder[T] { // coverage-ignore
+	b.timeout = timeout
+	return b
+}
+
+// Config sets the config of the dao, the set fields override the global config, see gdao.CfgOverride.
+func (b *baseDaoBuilder[T]) Config(cfg gdao.CfgOverride) *baseDaoBuilder[T] {
+	b.cfg = cfg
+	return b
+}
+
 func (b *baseDaoBuilder[T]) Build() *baseDao[T] {
 	if strings.TrimSpace(b.table) == "" {
 		panic("table must not be empty")
 	}
-	dao := gdao.DaoBuilder[T]().DB(b.db).AllowInvalidField(b.allowInvalidField).ColumnMapper(b.columnMapper).Build()
-	countDao := gdao.CountDaoBuilder().DB(b.db).Build()
-	return &baseDao[T]{Dao: dao, CountDao: countDao, table: b.table}
+	dao := gdao.DaoBuilder[T]().DB(b.db).Resolver(b.resolver).Timeout(b.timeout).Table(b.table).AllowInvalidField(b.allowInvalidField).ColumnMapper(b.columnMapper).Config(b.cfg).Build()
+	countDao := gdao.CountDaoBuilder().DB(b.db).Resolver(b.resolver).Timeout(b.timeout).Table(b.table).Config(b.cfg).Build()
+	return &baseDao[T]{Dao: dao, CountDao: countDao, table: b.table, strict: b.strict, sharding: b.sharding}
 }
 
 func BaseDaoBuilder[T any]() *baseDaoBuilder[T] {
@@ -826,7 +1530,9 @@ type Cond interface {
 	len() int
 	setNot()
 	setParenthesized()
-	write(nameMap map[string]string, b *gdao.BaseSqlBuilder)
+	write(m columnMapper, b *gdao.BaseSqlBuilder)
+	// match reports whether the values of the columns satisfy the condition, it is used by the fake.
+	match(value func(column string) (any, error)) (bool, error)
 }
 
 type baseCond struct {
@@ -846,6 +1552,12 @@ func (bc *baseCond) setParenthesized() {
 	bc.parenthesized = true
 }
 
+// doMatch negates the result of match if the condition is NOT.
+func (bc *baseCond) doMatch(match func() (bool, error)) (bool, error) {
+	ok, err := match()
+	return ok != bc.not, err
+}
+
 func (bc *baseCond) doWrite(b *gdao.BaseSqlBuilder, write func()) {
 	if bc.not {
 		b.Write("NOT ")
@@ -884,7 +1596,7 @@ func (cs *conds) len() int {
 	return len(cs.cs)
 }
 
-func (cs *conds) write(nameMap map[string]string, b *gdao.BaseSqlBuilder) {
+func (cs *conds) write(m columnMapper, b *gdao.BaseSqlBuilder) {
 	cs.doWrite(b, func() {
 		for i, cond := range cs.cs {
 			if i != 0 {
@@ -894,11 +1606,23 @@ func (cs *conds) write(nameMap map[string]string, b *gdao.BaseSqlBuilder) {
 					b.Write(" AND ")
 				}
 			}
-			cond.write(nameMap, b)
+			cond.write(m, b)
 		}
 	})
 }
 
+func (cs *conds) match(value func(column string) (any, error)) (bool, error) {
+	return cs.doMatch(func() (bool, error) {
+		for _, cond := range cs.cs {
+			ok, err := cond.match(value)
+			if err != nil || ok == cs.or {
+				return ok, err
+			}
+		}
+		return !cs.or, nil
+	})
+}
+
 func (cs *conds) addCond(c Cond) *conds {
 	if c != nil && c.len() > 0 {
 		if cs.nextNot {
@@ -915,7 +1639,7 @@ func (cs *conds) addCond(c Cond) *conds {
 
 func (cs *conds) ToStrArgs(nameMap map[string]string) (string, []any) {
 	b := newTempSqlBuilder()
-	cs.write(nameMap, b.BaseSqlBuilder)
+	cs.write(fieldNameMap(nameMap), b.BaseSqlBuilder)
 	return b.Sql(), b.Args()
 }
 
@@ -1097,12 +1821,16 @@ type condPlain struct {
 	args []any
 }
 
-func (c *condPlain) write(_ map[string]string, b *gdao.BaseSqlBuilder) {
+func (c *condPlain) write(_ columnMapper, b *gdao.BaseSqlBuilder) {
 	c.doWrite(b, func() {
 		b.Write(c.sql, c.args...)
 	})
 }
 
+func (c *condPlain) match(func(column string) (any, error)) (bool, error) {
+	return false, errors.New("plain condition is not supported by the fake: " + c.sql)
+}
+
 type condBinOp struct {
 	baseCond
 	column string
@@ -1110,13 +1838,41 @@ type condBinOp struct {
 	arg    any
 }
 
-func (c *condBinOp) write(nameMap map[string]string, b *gdao.BaseSqlBuilder) {
+func (c *condBinOp) write(m columnMapper, b *gdao.BaseSqlBuilder) {
 	c.doWrite(b, func() {
-		b.Write(mapColumn(nameMap, c.column))
+		b.Write(m.mapColumn(b, c.column))
 		b.Write(" ")
 		b.Write(c.op)
 		b.Write(" ")
-		b.Write("?", c.arg)
+		b.Write("?", m.mapArg(c.column, c.arg))
+	})
+}
+
+func (c *condBinOp) match(value func(column string) (any, error)) (bool, error) {
+	return c.doMatch(func() (bool, error) {
+		v, err := value(c.column)
+		if err != nil || isNull(v) || isNull(c.arg) {
+			return false, err
+		}
+		if c.op == "LIKE" {
+			s, ok := v.(string)
+			return ok && gdao.MatchLike(s, c.arg.(string)), nil
+		}
+		r, err := gdao.CompareValues(v, c.arg)
+		switch c.op {
+		case "=":
+			return r == 0, err
+		case "<>":
+			return r != 0, err
+		case ">":
+			return r > 0, err
+		case "<":
+			return r < 0, err
+		case ">=":
+			return r >= 0, err
+		default:
+			return r <= 0, err
+		}
 	})
 }
 
@@ -1126,9 +1882,9 @@ type condIn struct {
 	args   []any
 }
 
-func (c *condIn) write(nameMap map[string]string, b *gdao.BaseSqlBuilder) {
+func (c *condIn) write(m columnMapper, b *gdao.BaseSqlBuilder) {
 	c.doWrite(b, func() {
-		b.Write(mapColumn(nameMap, c.column))
+		b.Write(m.mapColumn(b, c.column))
 		b.Write(" IN(")
 		for i := 0; i < len(c.args); i++ {
 			if i != 0 {
@@ -1136,7 +1892,25 @@ func (c *condIn) write(nameMap map[string]string, b *gdao.BaseSqlBuilder) {
 			}
 			b.Write("?")
 		}
-		b.Write(")", c.args...)
+		for _, arg := range c.args {
+			b.SetArgs(m.mapArg(c.column, arg))
+		}
+		b.Write(")")
+	})
+}
+
+func (c *condIn) match(value func(column string) (any, error)) (bool, error) {
+	return c.doMatch(func() (bool, error) {
+		v, err := value(c.column)
+		if err != nil || isNull(v) {
+			return false, err
+		}
+		for _, arg := range c.args {
+			if r, err := gdao.CompareValues(v, arg); err != nil || r == 0 && !isNull(arg) {
+				return err == nil, err
+			}
+		}
+		return false, nil
 	})
 }
 
@@ -1146,10 +1920,25 @@ type condBetween struct {
 	min, max any
 }
 
-func (c *condBetween) write(nameMap map[string]string, b *gdao.BaseSqlBuilder) {
+func (c *condBetween) write(m columnMapper, b *gdao.BaseSqlBuilder) {
 	c.doWrite(b, func() {
-		b.Write(mapColumn(nameMap, c.column))
-		b.Write(" BETWEEN ? AND ?", c.min, c.max)
+		b.Write(m.mapColumn(b, c.column))
+		b.Write(" BETWEEN ? AND ?", m.mapArg(c.column, c.min), m.mapArg(c.column, c.max))
+	})
+}
+
+func (c *condBetween) match(value func(column string) (any, error)) (bool, error) {
+	return c.doMatch(func() (bool, error) {
+		v, err := value(c.column)
+		if err != nil || isNull(v) || isNull(c.min) || isNull(c.max) {
+			return false, err
+		}
+		r1, err := gdao.CompareValues(v, c.min)
+		if err != nil {
+			return false, err
+		}
+		r2, err := gdao.CompareValues(v, c.max)
+		return r1 >= 0 && r2 <= 0, err
 	})
 }
 
@@ -1159,9 +1948,9 @@ type condIsNull struct {
 	column  string
 }
 
-func (c *condIsNull) write(nameMap map[string]string, b *gdao.BaseSqlBuilder) {
+func (c *condIsNull) write(m columnMapper, b *gdao.BaseSqlBuilder) {
 	c.doWrite(b, func() {
-		b.Write(mapColumn(nameMap, c.column))
+		b.Write(m.mapColumn(b, c.column))
 		b.Write(" IS")
 		if c.notNull {
 			b.Write(" NOT")
@@ -1170,6 +1959,13 @@ func (c *condIsNull) write(nameMap map[string]string, b *gdao.BaseSqlBuilder) {
 	})
 }
 
+func (c *condIsNull) match(value func(column string) (any, error)) (bool, error) {
+	return c.doMatch(func() (bool, error) {
+		v, err := value(c.column)
+		return isNull(v) != c.notNull, err
+	})
+}
+
 type inArgs []any
 
 func InArgs[T any](source ...T) inArgs {
@@ -1221,6 +2017,17 @@ type OdrBy struct {
 	items []odrByItem
 }
 
+// selectWith returns the selected columns appended with the missing ORDER BY columns, and the appended columns.
+func (o *OdrBy) selectWith(sel []string) ([]string, []string) {
+	var extra []string
+	for _, item := range o.items {
+		if !slices.Contains(sel, item.column) && !slices.Contains(extra, item.column) {
+			extra = append(extra, item.column)
+		}
+	}
+	return append(slices.Clip(sel), extra...), extra
+}
+
 func (o *OdrBy) Asc(column string) *OdrBy {
 	o.items = append(o.items, odrByItem{column: column, seq: asc})
 	return o
@@ -1231,10 +2038,10 @@ func (o *OdrBy) Desc(column string) *OdrBy {
 	return o
 }
 
-func (o *OdrBy) write(nameMap map[string]string, b *gdao.BaseSqlBuilder) {
+func (o *OdrBy) write(m columnMapper, b *gdao.BaseSqlBuilder) {
 	b.Repeat(len(o.items), b.SepFix(" ORDER BY ", ", ", "", false), nil, func(_, i int) {
 		item := o.items[i]
-		b.Write(mapColumn(nameMap, item.column)).Write(" ")
+		b.Write(m.mapColumn(b, item.column)).Write(" ")
 		b.Write(string(item.seq))
 	})
 }
@@ -1269,16 +2076,16 @@ func (o *OnDuplKey) SetPlain(column, value string) *OnDuplKey {
 	return o
 }
 
-func (o *OnDuplKey) write(nameMap map[string]string, b *gdao.BaseSqlBuilder) {
+func (o *OnDuplKey) write(m columnMapper, b *gdao.BaseSqlBuilder) {
 	b.Write(" ON DUPLICATE KEY UPDATE ")
 	b.Repeat(len(o.items), b.Sep(", "), nil, func(_, i int) {
 		item := o.items[i]
-		b.Write(mapColumn(nameMap, item.column))
+		b.Write(m.mapColumn(b, item.column))
 		b.Write(" = ")
 		if item.plain {
 			b.Write(item.value.(string))
 		} else {
-			b.Write("?", item.value)
+			b.Write("?", m.mapArg(item.column, item.value))
 		}
 	})
 }
@@ -1315,20 +2122,30 @@ func OnDuplicateKey() *OnDuplKey { return &OnDuplKey{} }
 //========================== Others ===========================
 //=============================================================
 
-func mapColumns(nameMap map[string]string, columns []string) []string {
-	var target []string
-	for _, column := range columns {
-		if c, ok := nameMap[column]; ok {
-			column = c
-		}
-		target = append(target, column)
-	}
-	return target
+// columnMapper resolves a field name or column name to a column of the table.
+type columnMapper interface {
+	mapColumn(b *gdao.BaseSqlBuilder, column string) string
+	// mapArg returns the arg bound to the column, it is marshalled if the column is tagged json, and marked to be
+	// redacted in the logs if the column is sensitive.
+	mapArg(column string, arg any) any
 }
 
-func mapColumn(nameMap map[string]string, column string) string {
-	if c, ok := nameMap[column]; ok {
+// fieldNameMap is a lenient columnMapper, the names not in it are written as they are.
+type fieldNameMap map[string]string
+
+func (m fieldNameMap) mapColumn(_ *gdao.BaseSqlBuilder, column string) string {
+	if c, ok := m[column]; ok {
 		column = c
 	}
 	return column
 }
+
+func (m fieldNameMap) mapArg(_ string, arg any) any {
+	return arg
+}
+
+// isNull reports whether v is nil or a nil pointer.
+func isNull(v any) bool {
+	r, err := gdao.CompareValues(v, nil)
+	return err == nil && r == 0
+}
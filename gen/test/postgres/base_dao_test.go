@@ -17,6 +17,8 @@
 package postgres_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -142,6 +144,41 @@ func TestBaseDao_InsertBatch(t *testing.T) {
 	r.Equal(int32(9), *u2.Id)
 }
 
+func TestBaseDao_InsertBatch_Chunk(t *testing.T) {
+	r := require.New(t)
+	d, mock := dao.MockBaseDao[User](r, "user")
+	mock.ExpectBegin()
+	mock.ExpectPrepare(`INSERT INTO user\(name, phone, email\) VALUES\(\$1, \$2, \$3\), \(\$4, \$5, \$6\) RETURNING id`).
+		ExpectQuery().WithArgs("abc", "12345", "email11", "def", "6789", "email22").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8).AddRow(9))
+	mock.ExpectPrepare(`INSERT INTO user\(name, phone, email\) VALUES\(\$1, \$2, \$3\) RETURNING id`).
+		ExpectQuery().WithArgs("ghi", "2468", "email33").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+	mock.ExpectCommit()
+
+	u := &User{
+		Name:  gdao.P("abc"),
+		Phone: gdao.P("12345"),
+		Email: gdao.P("email11"),
+	}
+	u2 := &User{
+		Name:  gdao.P("def"),
+		Phone: gdao.P("6789"),
+		Email: gdao.P("email22"),
+	}
+	u3 := &User{
+		Name:  gdao.P("ghi"),
+		Age:   gdao.P[int32](20),
+		Phone: gdao.P("2468"),
+		Email: gdao.P("email33"),
+	}
+	err := d.InsertBatch().Entities(u, u2, u3).ChunkSize(2).Do()
+
+	r.NoError(err)
+	r.NoError(mock.ExpectationsWereMet())
+	r.Equal(int32(8), *u.Id)
+	r.Equal(int32(9), *u2.Id)
+	r.Equal(int32(10), *u3.Id)
+}
+
 func TestBaseDao_Update(t *testing.T) {
 	r := require.New(t)
 	{
@@ -274,6 +311,74 @@ func TestBaseDao_Count(t *testing.T) {
 	r.Equal(int64(8), count.Int64())
 }
 
+func TestBaseDao_Strict(t *testing.T) {
+	r := require.New(t)
+	_, mock := dao.MockBaseDao[User](r, "user")
+	d := dao.BaseDaoBuilder[User]().Table("user").Strict(true).Build()
+	{
+		mock.ExpectPrepare(`SELECT id, name FROM user WHERE 1 = 1 ORDER BY create_at DESC`).
+			ExpectQuery().WillReturnRows(mock.NewRows([]string{"id", "name"}).AddRow(1, "lucy"))
+		list, err := d.List().Select("Id", "name").Condition(dao.And().Plain("1 = 1")).
+			OrderBy(dao.OrderBy().Desc("CreateAt")).Do()
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+		r.Len(list, 1)
+	}
+	{
+		var uce *gdao.UnknownColumnError
+		_, err := d.List().Select("id", "name; DROP TABLE user").Do()
+		r.True(errors.As(err, &uce))
+		r.Equal("name; DROP TABLE user", uce.Column)
+		_, err = d.List().OrderBy(dao.OrderBy().Asc("1")).Do()
+		r.True(errors.As(err, &uce))
+		r.Equal("1", uce.Column)
+		_, err = d.Count().Condition(dao.And().Eq("unknown", 1)).Do()
+		r.EqualError(err, `unknown column "unknown"`)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+}
+
+func TestBaseDao_Sharding(t *testing.T) {
+	r := require.New(t)
+	dao.MockBaseDao[User](r, "user")
+	db0, mock0, err := sqlmock.New()
+	r.NoError(err)
+	db1, mock1, err := sqlmock.New()
+	r.NoError(err)
+	type shardKey struct{}
+	shards := []gdao.Shard{{DB: db0, Suffix: "_0"}, {DB: db1, Suffix: "_1"}}
+	sharding := gdao.ShardingBuilder[User]().Shards(shards...).FanOut(true).
+		ShardFunc(func(ctx context.Context, entity *User) (gdao.Shard, bool) {
+			if level, ok := ctx.Value(shardKey{}).(int); ok {
+				return shards[level%2], true
+			}
+			return gdao.Shard{}, false
+		}).Build()
+	d := dao.BaseDaoBuilder[User]().Table("user").Sharding(sharding).Build()
+	{
+		mock0.ExpectPrepare(`SELECT COUNT\(\*\) FROM user_0 WHERE status = \$1`).
+			ExpectQuery().WithArgs(1).WillReturnRows(mock0.NewRows([]string{"count"}).AddRow(3))
+		mock1.ExpectPrepare(`SELECT COUNT\(\*\) FROM user_1 WHERE status = \$1`).
+			ExpectQuery().WithArgs(1).WillReturnRows(mock1.NewRows([]string{"count"}).AddRow(4))
+		count, err := d.Count().Condition(dao.And().Eq("status", 1)).Do()
+		r.NoError(err)
+		r.Equal(int64(7), count.Int64())
+	}
+	{
+		mock1.ExpectPrepare(`DELETE FROM user_1 WHERE status = \$1`).
+			ExpectExec().WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+		affected, err := d.Delete().Ctx(context.WithValue(context.Background(), shardKey{}, 1)).
+			Condition(dao.And().Eq("status", 1)).Do()
+		r.NoError(err)
+		r.Equal(int64(2), affected)
+
+		_, err = d.Delete().Condition(dao.And().Eq("status", 1)).Do()
+		r.ErrorIs(err, gdao.ErrNoShardKey)
+	}
+	r.NoError(mock0.ExpectationsWereMet())
+	r.NoError(mock1.ExpectationsWereMet())
+}
+
 func TestCond(t *testing.T) {
 	r := require.New(t)
 	{
@@ -390,3 +495,48 @@ func TestCondOpt(t *testing.T) {
 		r.NoError(err)
 	}
 }
+
+type Post struct {
+	Id     *int32   `gdao:"column=id;auto"`
+	Tags   []string `gdao:"column=tags"`
+	Scores []int32  `gdao:"column=scores"`
+}
+
+func TestBaseDao_Array(t *testing.T) {
+	r := require.New(t)
+	{
+		d, mock := dao.MockBaseDao[Post](r, "post")
+		mock.ExpectPrepare(`SELECT id, tags, scores FROM post WHERE tags @> \$1 AND scores && \$2 AND id = ANY\(\$3\)`).
+			ExpectQuery().WithArgs(`{"a","b"}`, `{1,2}`, `{1,3}`).WillReturnRows(mock.NewRows(nil))
+
+		_, err := d.List().Condition(dao.And().Contains("tags", []string{"a", "b"}).
+			Overlaps("scores", []int32{1, 2}).
+			EqAny("id", []int32{1, 3}).
+			Contains("tags", nil, dao.WithIfPresent())).Do()
+		r.NoError(err)
+	}
+	{
+		d, _ := dao.MockBaseDao[Post](r, "post")
+		restore := d.Fake(
+			&Post{Id: gdao.P[int32](1), Tags: []string{"a", "b", "c"}, Scores: []int32{1, 2}},
+			&Post{Id: gdao.P[int32](2), Tags: []string{"b"}, Scores: []int32{3}},
+			&Post{Id: gdao.P[int32](3)},
+		)
+		defer restore()
+
+		ids := func(c dao.Cond) []int32 {
+			list, err := d.List().Condition(c).OrderBy(dao.OrderBy().Asc("id")).Do()
+			r.NoError(err)
+			var ids []int32
+			for _, p := range list {
+				ids = append(ids, *p.Id)
+			}
+			return ids
+		}
+		r.Equal([]int32{1}, ids(dao.And().Contains("tags", []string{"a", "b"})))
+		r.Equal([]int32{1, 2}, ids(dao.And().Contains("tags", []string{"b"})))
+		r.Equal([]int32{2}, ids(dao.And().Overlaps("scores", []int32{3, 4})))
+		r.Equal([]int32{1, 3}, ids(dao.And().EqAny("id", []int32{1, 3, 5})))
+		r.Empty(ids(dao.And().EqAny("id", []int32{})))
+	}
+}
@@ -33,6 +33,14 @@ func TestSqlite(t *testing.T) {
 		OutPath:   "gen/test/sqlite/testdata",
 		TableCfg: gen.TableCfg{
 			Tables: gen.Tables{"test_table"},
+			Mappers: gen.Mappers{
+				"test_table": gen.Mappings{
+					"text":    gen.MappingJSON[[]string](),
+					"clob":    gen.MappingJSON[map[string]any](),
+					"numeric": gen.Mapping[string](),
+				},
+			},
+			Decimal: gen.MappingDecimal(),
 		},
 		DaoCfg: gen.DaoCfg{
 			CoverBaseDao:      true,
@@ -52,3 +52,17 @@ func IsBaseType(fte reflect.Type) bool {
 	}
 	return false
 }
+
+// IsArrayType 判断ft是否为基本类型、基本类型指针或[]byte的切片，可多维
+func IsArrayType(ft reflect.Type) bool {
+	if ft.Kind() != reflect.Slice {
+		return false
+	}
+	fte := ft.Elem()
+	switch {
+	case IsBaseType(fte), fte.Kind() == reflect.Pointer && IsBaseType(fte.Elem()):
+		return true
+	default:
+		return IsArrayType(fte)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// JSONCodec marshals the fields tagged json on writing and unmarshals them on scanning.
+type JSONCodec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// stdJSONCodec is the default JSONCodec using encoding/json.
+type stdJSONCodec struct{}
+
+func (stdJSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdJSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// jsonCodec returns the JSONCodec of the config, default is encoding/json.
+func (c *Cfg) jsonCodec() JSONCodec {
+	if c.JSONCodec != nil {
+		return c.JSONCodec
+	}
+	return stdJSONCodec{}
+}
+
+// isJSONType reports whether the field of type t can be tagged json, it must be a pointer to struct, a map or a slice.
+func isJSONType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return t.Elem().Kind() == reflect.Struct
+	case reflect.Map, reflect.Slice:
+		return true
+	default:
+		return false
+	}
+}
+
+// jsonArg is an arg marshalled to a JSON string when it is sent to the driver.
+type jsonArg struct {
+	value any
+	codec JSONCodec
+}
+
+func (a jsonArg) Value() (driver.Value, error) {
+	if v := reflect.ValueOf(a.value); !v.IsValid() || v.Kind() != reflect.Struct && v.IsNil() {
+		return nil, nil
+	}
+	data, err := a.codec.Marshal(a.value)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// String returns the JSON for the logs.
+func (a jsonArg) String() string {
+	value, err := a.Value()
+	if err != nil || value == nil {
+		return fmt.Sprint(a.value)
+	}
+	return value.(string)
+}
+
+// GoString returns the JSON, so that the cache keys are made from the content instead of the address.
+func (a jsonArg) GoString() string {
+	return a.String()
+}
+
+// unmarshalJSON unmarshals data into the field tagged json, the field is set to zero if data is NULL.
+func unmarshalJSON(codec JSONCodec, data []byte, field reflect.Value) error {
+	if data == nil {
+		field.SetZero()
+		return nil
+	}
+	p := reflect.New(field.Type())
+	if err := codec.Unmarshal(data, p.Interface()); err != nil {
+		return err
+	}
+	field.Set(p.Elem())
+	return nil
+}
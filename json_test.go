@@ -0,0 +1,150 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jishaocong0910/gdao"
+	"github.com/stretchr/testify/require"
+)
+
+type Profile struct {
+	Nickname string `json:"nickname"`
+	Age      int    `json:"age"`
+}
+
+type Customer struct {
+	Id      *int32            `gdao:"column=id;auto"`
+	Profile *Profile          `gdao:"column=profile;json"`
+	Tags    []string          `gdao:"column=tags;json"`
+	Attrs   map[string]string `gdao:"column=attrs;json;sensitive"`
+}
+
+type prefixCodec struct{}
+
+func (prefixCodec) Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	return append([]byte("#"), data...), err
+}
+
+func (prefixCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data[1:], v)
+}
+
+func TestDao_JSON(t *testing.T) {
+	r := require.New(t)
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+	log := &MockLogger{}
+	gdao.Config(gdao.Cfg{DefaultDB: db, Logger: log, LogLevel: gdao.LogLevel_.DEBUG})
+	dao := gdao.DaoBuilder[Customer]().Build()
+	r.True(dao.IsJSON("Profile"))
+	r.True(dao.IsJSON("tags"))
+	r.False(dao.IsJSON("id"))
+
+	a := &Customer{Profile: &Profile{Nickname: "foo", Age: 18}, Tags: []string{"a", "b"}, Attrs: map[string]string{"k": "v"}}
+	mock.ExpectPrepare(`INSERT INTO customer\(profile, tags, attrs\) VALUES\(\?, \?, \?\)`).ExpectExec().
+		WithArgs(`{"nickname":"foo","age":18}`, `["a","b"]`, `{"k":"v"}`).WillReturnResult(sqlmock.NewResult(1, 1))
+	_, err = dao.Exec().Entities(a).BuildSql(func(b *gdao.DaoSqlBuilder[Customer]) {
+		b.Write("INSERT INTO customer(profile, tags, attrs) VALUES")
+		b.EachColumn(b.Entity(), b.SepFix("(", ", ", ")", false), func(_ int, _ string, value any) {
+			b.Write("?", value)
+		}, "profile", "tags", "attrs")
+	}).Do()
+	r.NoError(err)
+	r.Equal([]any{`{"nickname":"foo","age":18}`, `["a","b"]`, `"***"`}, toStrings(log.args[1].([]any)))
+
+	mock.ExpectPrepare(`SELECT id, profile, tags, attrs FROM customer WHERE tags=\?`).ExpectQuery().WithArgs(`["a"]`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "profile", "tags", "attrs"}).
+			AddRow(1, []byte(`{"nickname":"foo","age":18}`), `["a"]`, nil).
+			AddRow(2, []byte(`null`), nil, `{}`))
+	_, list, err := dao.Query().BuildSql(func(b *gdao.DaoSqlBuilder[Customer]) {
+		b.Write("SELECT id, profile, tags, attrs FROM customer WHERE tags=?", dao.JSONArg([]string{"a"}))
+	}).Do()
+	r.NoError(err)
+	r.Len(list, 2)
+	r.Equal(&Profile{Nickname: "foo", Age: 18}, list[0].Profile)
+	r.Equal([]string{"a"}, list[0].Tags)
+	r.Nil(list[0].Attrs)
+	r.Nil(list[1].Profile)
+	r.Nil(list[1].Tags)
+	r.Equal(map[string]string{}, list[1].Attrs)
+
+	mock.ExpectPrepare(`SELECT profile FROM customer`).ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"profile"}).AddRow(`{`))
+	_, _, err = dao.Query().BuildSql(func(b *gdao.DaoSqlBuilder[Customer]) {
+		b.Write("SELECT profile FROM customer")
+	}).Do()
+	var e *gdao.Error
+	r.ErrorAs(err, &e)
+	r.Equal(gdao.Op_.QUERY, e.Op)
+
+	r.Equal("x", dao.JSONArg("x"))
+	r.Equal([]byte("x"), dao.JSONArg([]byte("x")))
+	r.Nil(dao.JSONArg(nil))
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func TestDao_JSONCodec(t *testing.T) {
+	r := require.New(t)
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+	gdao.Config(gdao.Cfg{DefaultDB: db})
+	dao := gdao.DaoBuilder[Customer]().Config(gdao.CfgOverride{JSONCodec: prefixCodec{}}).Build()
+
+	mock.ExpectPrepare(`UPDATE customer SET tags=\? WHERE id=\?`).ExpectExec().WithArgs(`#["a"]`, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err = dao.Exec().Entities(&Customer{Id: gdao.P[int32](1), Tags: []string{"a"}}).BuildSql(func(b *gdao.DaoSqlBuilder[Customer]) {
+		b.Write("UPDATE customer SET tags=?", b.ColumnValue(b.Entity(), "tags"))
+		b.Write(" WHERE id=?", b.ColumnValue(b.Entity(), "id"))
+	}).Do()
+	r.NoError(err)
+
+	mock.ExpectPrepare(`SELECT tags FROM customer`).ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"tags"}).AddRow(`#["b"]`))
+	first, _, err := dao.Query().BuildSql(func(b *gdao.DaoSqlBuilder[Customer]) {
+		b.Write("SELECT tags FROM customer")
+	}).Do()
+	r.NoError(err)
+	r.Equal([]string{"b"}, first.Tags)
+	r.NoError(mock.ExpectationsWereMet())
+
+	type Invalid struct {
+		Name *string `gdao:"column=name;json"`
+	}
+	r.PanicsWithError(`field "Name" of "gdao_test.Invalid" tagged json must be a pointer to struct, a map or a slice`, func() {
+		gdao.DaoBuilder[Invalid]().Build()
+	})
+
+	c := &Customer{Tags: []string{"a"}}
+	field := reflect.ValueOf(c).Elem().FieldByName("Tags")
+	r.NoError(gdao.UnmarshalJSON(prefixCodec{}, nil, field))
+	r.Nil(c.Tags)
+}
+
+func toStrings(args []any) []any {
+	for i, a := range args {
+		if s, ok := a.(interface{ String() string }); ok {
+			args[i] = s.String()
+		}
+	}
+	return args
+}
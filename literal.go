@@ -0,0 +1,283 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// literalSyntax writes the values as the literals of a dialect.
+type literalSyntax struct {
+	// the escaped characters of the strings except the single quote.
+	escapes *strings.Replacer
+	// the prefix of the strings, e.g. N of SQL Server.
+	strPrefix string
+	bytes     func(b []byte) string
+	time      func(t time.Time) string
+	// if true, the booleans are written as 1 and 0.
+	numericBool bool
+	// if false, ? is not a placeholder, e.g. the JSON operator of PostgreSQL.
+	question bool
+	// if true, the slices are written as the PostgreSQL arrays.
+	pgArrays bool
+}
+
+var standardLiteral = literalSyntax{
+	bytes:    func(b []byte) string { return "X'" + hex.EncodeToString(b) + "'" },
+	time:     func(t time.Time) string { return "TIMESTAMP '" + t.Format("2006-01-02 15:04:05.999999999") + "'" },
+	question: true,
+}
+
+var (
+	mysqlLiteral = literalSyntax{
+		escapes:  strings.NewReplacer(`\`, `\\`),
+		bytes:    standardLiteral.bytes,
+		time:     func(t time.Time) string { return "TIMESTAMP'" + t.Format("2006-01-02 15:04:05.999999") + "'" },
+		question: true,
+	}
+	postgresLiteral = literalSyntax{
+		bytes:    func(b []byte) string { return `'\x` + hex.EncodeToString(b) + "'::bytea" },
+		time:     func(t time.Time) string { return "TIMESTAMPTZ '" + t.Format("2006-01-02 15:04:05.999999Z07:00") + "'" },
+		pgArrays: true,
+	}
+	oracleLiteral = literalSyntax{
+		bytes: func(b []byte) string { return "HEXTORAW('" + hex.EncodeToString(b) + "')" },
+		time: func(t time.Time) string {
+			return "TIMESTAMP '" + t.Format("2006-01-02 15:04:05.999999999 -07:00") + "'"
+		},
+		numericBool: true,
+	}
+	sqlserverLiteral = literalSyntax{
+		strPrefix: "N",
+		bytes:     func(b []byte) string { return "0x" + hex.EncodeToString(b) },
+		time: func(t time.Time) string {
+			return "CAST('" + t.Format("2006-01-02T15:04:05.9999999Z07:00") + "' AS DATETIMEOFFSET)"
+		},
+		numericBool: true,
+		question:    true,
+	}
+	sqliteLiteral = literalSyntax{
+		bytes:       standardLiteral.bytes,
+		time:        func(t time.Time) string { return "'" + t.Format("2006-01-02 15:04:05.999999999-07:00") + "'" },
+		numericBool: true,
+		question:    true,
+	}
+)
+
+func (d Dialect) literalSyntax() *literalSyntax {
+	if d.IsUndefined() {
+		return &standardLiteral
+	}
+	return d.literal
+}
+
+// InlineSql returns sql with the args inlined as the literals of the dialect, the placeholders ?, $n, :n, @pn and the
+// names of sql.NamedArg are resolved. It is only for displaying, the result is not guaranteed to be executable as is.
+func (d Dialect) InlineSql(_sql string, args []any) string {
+	args = convertArgs(slices.Clone(args))
+	ls := d.literalSyntax()
+	var b strings.Builder
+	var next int
+	arg := func(i int) (string, bool) {
+		if i < 0 || i >= len(args) {
+			return "", false
+		}
+		return ls.write(args[i]), true
+	}
+	named := func(name string) (string, bool) {
+		for _, a := range args {
+			if na, ok := a.(sql.NamedArg); ok && na.Name == name {
+				return ls.write(na.Value), true
+			}
+		}
+		return "", false
+	}
+	for i := 0; i < len(_sql); {
+		c := _sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := closingQuote(_sql, i+1, c, ls.escapes != nil)
+			b.WriteString(_sql[i:end])
+			i = end
+			continue
+		case c == '-' && strings.HasPrefix(_sql[i:], "--"):
+			end := strings.IndexByte(_sql[i:], '\n')
+			if end < 0 {
+				end = len(_sql) - i
+			}
+			b.WriteString(_sql[i : i+end])
+			i += end
+			continue
+		case c == '/' && strings.HasPrefix(_sql[i:], "/*"):
+			end := strings.Index(_sql[i+2:], "*/")
+			if end < 0 {
+				end = len(_sql) - i - 2
+			} else {
+				end += 2
+			}
+			b.WriteString(_sql[i : i+2+end])
+			i += 2 + end
+			continue
+		case c == '?' && ls.question:
+			if s, ok := arg(next); ok {
+				b.WriteString(s)
+				next++
+				i++
+				continue
+			}
+		case (c == '$' || c == ':' || c == '@') && (i == 0 || _sql[i-1] != ':' && _sql[i-1] != c):
+			j := i + 1
+			if c == '@' && j < len(_sql) && (_sql[j] == 'p' || _sql[j] == 'P') && j+1 < len(_sql) && isDigit(_sql[j+1]) {
+				j++
+			}
+			k := j
+			for k < len(_sql) && isDigit(_sql[k]) {
+				k++
+			}
+			if k > j {
+				n, _ := strconv.Atoi(_sql[j:k])
+				if s, ok := arg(n - 1); ok {
+					b.WriteString(s)
+					i = k
+					continue
+				}
+			} else if c != '$' {
+				for k < len(_sql) && isIdentChar(_sql[k]) {
+					k++
+				}
+				if s, ok := named(_sql[j:k]); ok && k > j {
+					b.WriteString(s)
+					i = k
+					continue
+				}
+			}
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String()
+}
+
+// closingQuote returns the index after the closing quote, the doubled quotes are escaped, and the characters after
+// the backslashes are escaped too if backslash is true.
+func closingQuote(s string, start int, quote byte, backslash bool) int {
+	for i := start; i < len(s); i++ {
+		if backslash && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// write returns the literal of the value, the pointers are dereferenced and the driver.Valuer is valued.
+func (ls *literalSyntax) write(value any) string {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "NULL"
+		}
+		if _, ok := v.Interface().(driver.Valuer); ok {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "NULL"
+	}
+	value = v.Interface()
+	switch x := value.(type) {
+	case Decimal:
+		return x.String()
+	case *Decimal:
+		return x.String()
+	}
+	if valuer, ok := value.(driver.Valuer); ok {
+		dv, err := valuer.Value()
+		if err != nil {
+			return ls.str(fmt.Sprint(value))
+		}
+		if dv == nil {
+			return "NULL"
+		}
+		value, v = dv, reflect.ValueOf(dv)
+	}
+	switch x := value.(type) {
+	case time.Time:
+		return ls.time(x)
+	case []byte:
+		return ls.bytes(x)
+	}
+	switch {
+	case v.CanInt():
+		return strconv.FormatInt(v.Int(), 10)
+	case v.CanUint():
+		return strconv.FormatUint(v.Uint(), 10)
+	case v.CanFloat():
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case v.Kind() == reflect.Bool:
+		switch {
+		case ls.numericBool && v.Bool():
+			return "1"
+		case ls.numericBool:
+			return "0"
+		case v.Bool():
+			return "TRUE"
+		default:
+			return "FALSE"
+		}
+	case v.Kind() == reflect.String:
+		return ls.str(v.String())
+	case ls.pgArrays && isPgArrayType(v.Type()):
+		if v.IsNil() {
+			return "NULL"
+		}
+		return ls.str(pgArray{v: v}.String())
+	default:
+		return ls.str(fmt.Sprint(value))
+	}
+}
+
+func (ls *literalSyntax) str(s string) string {
+	if ls.escapes != nil {
+		s = ls.escapes.Replace(s)
+	}
+	return ls.strPrefix + "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
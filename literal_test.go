@@ -0,0 +1,50 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jishaocong0910/gdao"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialect_InlineSql(t *testing.T) {
+	r := require.New(t)
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 120000000, time.UTC)
+	args := []any{gdao.P("it's"), nil, (*int)(nil), 3, 1.5, true, tm, []byte{0xab, 0x01}}
+
+	r.Equal(`SELECT '?', "?" FROM t -- ?
+WHERE a='it\\''s' AND b='it''s' /* ? */ AND c=NULL AND d=NULL AND e=3 AND f=1.5 AND g=TRUE AND h=TIMESTAMP'2024-05-06 07:08:09.12' AND i=X'ab01'`,
+		gdao.Dialect_.MYSQL.InlineSql(`SELECT '?', "?" FROM t -- ?
+WHERE a=? AND b=? /* ? */ AND c=? AND d=? AND e=? AND f=? AND g=? AND h=? AND i=?`,
+			append([]any{gdao.P(`it\'s`)}, args...)))
+	r.Equal(`SELECT a::text FROM t WHERE j ? 'k' AND a='it''s' AND e=3 AND g=TRUE AND h=TIMESTAMPTZ '2024-05-06 07:08:09.12Z' AND i='\xab01'::bytea AND x=$9`,
+		gdao.Dialect_.POSTGRES.InlineSql(`SELECT a::text FROM t WHERE j ? 'k' AND a=$1 AND e=$4 AND g=$6 AND h=$7 AND i=$8 AND x=$9`, args))
+	r.Equal(`UPDATE t SET a='it''s', g=1, h=TIMESTAMP '2024-05-06 07:08:09.12 +00:00', i=HEXTORAW('ab01') WHERE id='x' AND ts=TO_DATE('2024', 'YYYY')`,
+		gdao.Dialect_.ORACLE.InlineSql(`UPDATE t SET a=:1, g=:6, h=:7, i=:8 WHERE id=:id AND ts=TO_DATE('2024', 'YYYY')`,
+			append(args, sql.Named("id", "x"))))
+	r.Equal(`SELECT @@ROWCOUNT, N'it''s', 0, CAST('2024-05-06T07:08:09.12Z' AS DATETIMEOFFSET), 0xab01, N'y'`,
+		gdao.Dialect_.SQLSERVER.InlineSql(`SELECT @@ROWCOUNT, @p1, @p2, @p3, @p4, @name`,
+			[]any{"it's", false, tm, []byte{0xab, 0x01}, sql.Named("name", "y")}))
+	r.Equal(`SELECT 1 FROM t WHERE g=1 AND h='2024-05-06 07:08:09.12+00:00' AND z=?`,
+		gdao.Dialect_.SQLITE.InlineSql(`SELECT 1 FROM t WHERE g=? AND h=? AND z=?`, []any{true, tm}))
+	r.Equal(`SELECT TIMESTAMP '2024-05-06 07:08:09.12', X'ab01', 'a', TRUE`,
+		gdao.Dialect_.Undefined().InlineSql(`SELECT ?, ?, ?, ?`, []any{tm, []byte{0xab, 0x01}, sql.NullString{String: "a", Valid: true}, true}))
+}
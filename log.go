@@ -18,6 +18,8 @@ package gdao
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -31,8 +33,8 @@ type Logger interface {
 	Errorf(ctx context.Context, msg string, args ...any)
 }
 
-func formatSql(sql string) string {
-	if global.CompressSqlLog {
+func (c *Cfg) formatSql(sql string) string {
+	if c.CompressSqlLog {
 		sql = strings.TrimSpace(sql)
 		var line strings.Builder
 		chars := []rune(sql)
@@ -52,21 +54,40 @@ func formatSql(sql string) string {
 	return sql
 }
 
-func printSql(ctx context.Context, logLevel LogLevel, desc string, sql string, args []any, affected, rowCounts int64, err error) {
+func (c *Cfg) printSql(ctx context.Context, db *sql.DB, logLevel LogLevel, desc string, sql string, args []any, affected, rowCounts int64, err error) {
+	c.printMarkedSql(ctx, db, "", logLevel, desc, sql, args, affected, rowCounts, err)
+}
+
+// printMarkedSql prints the sql log with a marker at the beginning, such as "DRY RUN". The args are inlined into the
+// sql by the dialect of db if InlineSqlLog is true.
+func (c *Cfg) printMarkedSql(ctx context.Context, db *sql.DB, marker string, logLevel LogLevel, desc string, sql string, args []any, affected, rowCounts int64, err error) {
 	if logLevel.IsUndefined() {
-		logLevel = global.LogLevel
+		logLevel = c.LogLevel
 	}
 	if logLevel.Not(LogLevel_.DEBUG, LogLevel_.INFO) { // coverage-ignore
 		return
 	}
 	var msg strings.Builder
 	msgArgs := make([]any, 0, 5+len(args))
+	if marker != "" {
+		msg.WriteString(marker + ", ")
+	}
+	if tc := getTxCtx(ctx); tc != nil && tc.label != "" {
+		msg.WriteString("Tx: %s, ")
+		msgArgs = append(msgArgs, tc.label)
+	}
 	if desc != "" {
 		msg.WriteString("Desc: %s, ")
 		msgArgs = append(msgArgs, desc)
 	}
-	msg.WriteString("SQL: %s;")
-	msgArgs = append(msgArgs, formatSql(sql))
+	if c.InlineSqlLog {
+		msg.WriteString("SQL (inlined, display only): %s;")
+		msgArgs = append(msgArgs, c.formatSql(DialectOf(db).InlineSql(sql, args)))
+		args = nil
+	} else {
+		msg.WriteString("SQL: %s;")
+		msgArgs = append(msgArgs, c.formatSql(sql))
+	}
 
 	sep := " "
 	if len(args) > 0 {
@@ -114,32 +135,56 @@ func printSql(ctx context.Context, logLevel LogLevel, desc string, sql string, a
 
 	if err != nil {
 		msg.WriteString(sep)
+		if errors.Is(err, ErrTimeout) {
+			msg.WriteString("TIMEOUT, ")
+		}
 		msg.WriteString("error: %+v")
 		msgArgs = append(msgArgs, err)
 	}
 
-	printSqlLog(ctx, logLevel, err != nil, msg.String(), msgArgs...)
+	c.printSqlLog(ctx, logLevel, err != nil, msg.String(), msgArgs...)
 }
 
-func printSqlLog(ctx context.Context, logLevel LogLevel, hasError bool, msg string, args ...any) {
-	if global.Logger == nil { // coverage-ignore
+func (c *Cfg) printSqlLog(ctx context.Context, logLevel LogLevel, hasError bool, msg string, args ...any) {
+	if c.Logger == nil { // coverage-ignore
 		return
 	}
 	if hasError {
-		global.Logger.Errorf(ctx, msg, args...)
+		c.Logger.Errorf(ctx, msg, args...)
 	} else {
 		switch logLevel.String() {
 		case LogLevel_.DEBUG.String():
-			global.Logger.Debugf(ctx, msg, args...)
+			c.Logger.Debugf(ctx, msg, args...)
 		case LogLevel_.INFO.String():
-			global.Logger.Infof(ctx, msg, args...)
+			c.Logger.Infof(ctx, msg, args...)
 		}
 	}
 }
 
+// printWarn prints the warning by the global config.
 func printWarn(ctx context.Context, err error) {
-	if global.Logger == nil || err == nil { // coverage-ignore
+	globalCfg().printWarn(ctx, err)
+}
+
+func (c *Cfg) printWarn(ctx context.Context, err error) {
+	if c.Logger == nil || err == nil { // coverage-ignore
 		return
 	}
-	global.Logger.Warnf(ctx, fmt.Sprintf("%v", err))
+	c.Logger.Warnf(ctx, fmt.Sprintf("%v", err))
+}
+
+func (c *Cfg) printExplainWarn(ctx context.Context, desc, sql string, findings []string) {
+	if c.Logger == nil || len(findings) == 0 { // coverage-ignore
+		return
+	}
+	var msg strings.Builder
+	var msgArgs []any
+	msg.WriteString("EXPLAIN, ")
+	if desc != "" {
+		msg.WriteString("Desc: %s, ")
+		msgArgs = append(msgArgs, desc)
+	}
+	msg.WriteString("SQL: %s; %s")
+	msgArgs = append(msgArgs, c.formatSql(sql), strings.Join(findings, ", "))
+	c.Logger.Warnf(ctx, msg.String(), msgArgs...)
 }
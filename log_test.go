@@ -21,6 +21,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jishaocong0910/gdao"
 	"github.com/stretchr/testify/require"
 )
@@ -54,8 +55,8 @@ func TestPrintSql(t *testing.T) {
 	r := require.New(t)
 	{
 		log := &MockLogger{}
-		gdao.Config(gdao.Cfg{nil, log, gdao.LogLevel_.DEBUG, false})
-		gdao.PrintSql(nil, gdao.LogLevel_.Undefined(), "update a user", "UPDATE user SET status=?,phone=?,email=? WHERE level=?)", []any{2, nil, (*int)(nil), gdao.P("abc")}, 15, -1, errors.New("error"))
+		gdao.Config(gdao.Cfg{Logger: log, LogLevel: gdao.LogLevel_.DEBUG})
+		gdao.PrintSql(nil, nil, gdao.LogLevel_.Undefined(), "update a user", "UPDATE user SET status=?,phone=?,email=? WHERE level=?)", []any{2, nil, (*int)(nil), gdao.P("abc")}, 15, -1, errors.New("error"))
 		r.Equal(`Desc: %s, SQL: %s; args: %v, affected: %d, error: %+v`, log.msg)
 		r.Len(log.args, 5)
 		r.Equal("update a user", log.args[0])
@@ -71,8 +72,8 @@ func TestPrintSql(t *testing.T) {
 	}
 	{
 		log := &MockLogger{}
-		gdao.Config(gdao.Cfg{nil, log, gdao.LogLevel_.DEBUG, true})
-		gdao.PrintSql(nil, gdao.LogLevel_.Undefined(),
+		gdao.Config(gdao.Cfg{Logger: log, LogLevel: gdao.LogLevel_.DEBUG, CompressSqlLog: true})
+		gdao.PrintSql(nil, nil, gdao.LogLevel_.Undefined(),
 			"", `  
 SELECT *
   FROM
@@ -82,10 +83,66 @@ user`, nil, -1, 10, nil)
 	}
 }
 
+func TestPrintSql_Inline(t *testing.T) {
+	r := require.New(t)
+	log := &MockLogger{}
+	gdao.Config(gdao.Cfg{Logger: log, LogLevel: gdao.LogLevel_.DEBUG, InlineSqlLog: true})
+	gdao.PrintSql(nil, nil, gdao.LogLevel_.Undefined(), "", "SELECT * FROM user WHERE name=? AND level=?", []any{gdao.P("o'neil"), 3}, -1, 1, nil)
+	r.Equal("SQL (inlined, display only): %s; row counts: %d", log.msg)
+	r.Equal("SELECT * FROM user WHERE name='o''neil' AND level=3", log.args[0])
+	r.Equal(int64(1), log.args[1])
+}
+
 func TestPrintWarn(t *testing.T) {
 	r := require.New(t)
 	log := &MockLogger{}
-	gdao.Config(gdao.Cfg{nil, log, gdao.LogLevel_.DEBUG, false})
+	gdao.Config(gdao.Cfg{Logger: log, LogLevel: gdao.LogLevel_.DEBUG})
 	gdao.PrintWarn(nil, errors.New("warn"))
 	r.Equal("warn", log.msg)
 }
+
+type Member struct {
+	Id       *int32  `gdao:"column=id;auto"`
+	Name     *string `gdao:"column=name"`
+	Phone    *string `gdao:"column=phone;sensitive"`
+	Password []byte  `gdao:"column=password; sensitive"`
+}
+
+func TestPrintSql_Sensitive(t *testing.T) {
+	r := require.New(t)
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+	log := &MockLogger{}
+	gdao.Config(gdao.Cfg{DefaultDB: db, Logger: log, LogLevel: gdao.LogLevel_.DEBUG})
+	dao := gdao.DaoBuilder[Member]().Build()
+	r.True(dao.IsSensitive("Phone"))
+	r.True(dao.IsSensitive("password"))
+	r.False(dao.IsSensitive("name"))
+
+	m := &Member{Id: gdao.P[int32](1), Name: gdao.P("foo"), Phone: gdao.P("13800000000"), Password: []byte("secret")}
+	mock.ExpectPrepare(`UPDATE member SET name=\?, phone=\?, password=\? WHERE id=\? AND phone=\?`).ExpectExec().
+		WithArgs("foo", "13800000000", []byte("secret"), 1, "13900000000").WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err = dao.Exec().Entities(m).BuildSql(func(b *gdao.DaoSqlBuilder[Member]) {
+		b.Write("UPDATE member SET ")
+		b.EachColumn(b.Entity(), b.Sep(", "), func(_ int, column string, value any) {
+			b.Write(column+"=?", value)
+		}, "name", "phone", "password")
+		b.Write(" WHERE id=?", b.ColumnValue(b.Entity(), "id"))
+		b.Write(" AND phone=?", gdao.SensitiveArg("phone", "13900000000"))
+	}).Do()
+	r.NoError(err)
+	r.Equal([]any{`"foo"`, `"***"`, `"***"`, int32(1), `"***"`}, log.args[1])
+
+	gdao.Config(gdao.Cfg{DefaultDB: db, Logger: log, LogLevel: gdao.LogLevel_.DEBUG, Redact: func(column string, arg any) any {
+		return column + ":" + (*arg.(*string))[7:]
+	}})
+	mock.ExpectPrepare(`SELECT id FROM member WHERE phone=\?`).ExpectQuery().WithArgs("13800000000").WillReturnError(errors.New("boom"))
+	_, _, err = dao.Query().Entities(m).BuildSql(func(b *gdao.DaoSqlBuilder[Member]) {
+		b.Write("SELECT id FROM member WHERE phone=?", b.ColumnValue(b.Entity(), "phone"))
+	}).Do()
+	var e *gdao.Error
+	r.ErrorAs(err, &e)
+	r.Equal([]any{"phone:0000"}, e.Args)
+	r.Equal([]any{`"phone:0000"`}, log.args[1])
+	r.NoError(mock.ExpectationsWereMet())
+}
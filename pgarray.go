@@ -0,0 +1,357 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jishaocong0910/gdao/internal"
+)
+
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// pgArray encodes and decodes a slice as a PostgreSQL array literal, e.g. {1,2,NULL} and {{"a","b"},{"c","d"}}.
+type pgArray struct {
+	// the slice for writing, or the pointer to the slice for scanning.
+	v reflect.Value
+}
+
+// PgArray returns the arg of a PostgreSQL array for a slice, such as []int32, [][]string and []*string whose nil
+// elements are NULL. In PostgreSQL, the slice args are converted automatically, it is for the other drivers.
+func PgArray(value any) any {
+	if _, ok := value.(pgArray); ok {
+		return value
+	}
+	return pgArray{v: reflect.ValueOf(value)}
+}
+
+// isPgArrayType reports whether t is a slice which is encoded as a PostgreSQL array, []byte is a bytea.
+func isPgArrayType(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t != bytesType && internal.IsArrayType(t)
+}
+
+func (a pgArray) Value() (driver.Value, error) {
+	v := a.v
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.IsNil() {
+		return nil, nil
+	}
+	var b strings.Builder
+	if err := writePgArray(&b, v); err != nil {
+		return nil, err
+	}
+	return b.String(), nil
+}
+
+// String returns the array literal for the logs.
+func (a pgArray) String() string {
+	value, err := a.Value()
+	if err != nil {
+		return fmt.Sprint(a.v)
+	}
+	if value == nil {
+		return "NULL"
+	}
+	return value.(string)
+}
+
+func (a pgArray) Scan(src any) error {
+	field := a.v.Elem()
+	var s string
+	switch x := src.(type) {
+	case nil:
+		field.SetZero()
+		return nil
+	case []byte:
+		s = string(x)
+	case string:
+		s = x
+	default:
+		return fmt.Errorf("cannot scan %T into %s", src, field.Type())
+	}
+	// the dimensions decoration, e.g. [0:1]={1,2}
+	if strings.HasPrefix(s, "[") {
+		if i := strings.Index(s, "="); i >= 0 {
+			s = s[i+1:]
+		}
+	}
+	p := &pgArrayParser{s: s}
+	node, err := p.parse()
+	if err != nil {
+		return err
+	}
+	if p.i != len(p.s) {
+		return errors.New("invalid PostgreSQL array: " + s)
+	}
+	return decodePgArray(node, field)
+}
+
+func writePgArray(b *strings.Builder, v reflect.Value) error {
+	b.WriteByte('{')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		e := v.Index(i)
+		if e.Kind() == reflect.Pointer {
+			if e.IsNil() {
+				b.WriteString("NULL")
+				continue
+			}
+			e = e.Elem()
+		}
+		if e.Kind() == reflect.Slice && e.Type() != bytesType {
+			if e.IsNil() {
+				b.WriteString("NULL")
+				continue
+			}
+			if err := writePgArray(b, e); err != nil {
+				return err
+			}
+			continue
+		}
+		switch x := e.Interface().(type) {
+		case time.Time:
+			writePgString(b, x.Format(time.RFC3339Nano))
+		case []byte:
+			if x == nil {
+				b.WriteString("NULL")
+			} else {
+				writePgString(b, `\x`+hex.EncodeToString(x))
+			}
+		default:
+			switch {
+			case e.CanInt():
+				b.WriteString(strconv.FormatInt(e.Int(), 10))
+			case e.CanUint():
+				b.WriteString(strconv.FormatUint(e.Uint(), 10))
+			case e.CanFloat():
+				b.WriteString(strconv.FormatFloat(e.Float(), 'g', -1, e.Type().Bits()))
+			case e.Kind() == reflect.Bool:
+				if e.Bool() {
+					b.WriteByte('t')
+				} else {
+					b.WriteByte('f')
+				}
+			case e.Kind() == reflect.String:
+				writePgString(b, e.String())
+			default:
+				return fmt.Errorf("unsupported element type %s of PostgreSQL array", e.Type())
+			}
+		}
+	}
+	b.WriteByte('}')
+	return nil
+}
+
+// writePgString writes s as a quoted element, so that the commas, braces, spaces and "NULL" are kept.
+func writePgString(b *strings.Builder, s string) {
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('"')
+}
+
+// pgArrayNode is an element of a PostgreSQL array, it is a sub array if elems is not nil.
+type pgArrayNode struct {
+	null  bool
+	text  string
+	elems []pgArrayNode
+}
+
+type pgArrayParser struct {
+	s string
+	i int
+}
+
+func (p *pgArrayParser) parse() (pgArrayNode, error) {
+	if p.i >= len(p.s) || p.s[p.i] != '{' {
+		return pgArrayNode{}, errors.New("invalid PostgreSQL array: " + p.s)
+	}
+	p.i++
+	node := pgArrayNode{elems: []pgArrayNode{}}
+	if p.i < len(p.s) && p.s[p.i] == '}' {
+		p.i++
+		return node, nil
+	}
+	for {
+		var elem pgArrayNode
+		var err error
+		switch {
+		case p.i >= len(p.s):
+			return node, errors.New("invalid PostgreSQL array: " + p.s)
+		case p.s[p.i] == '{':
+			elem, err = p.parse()
+		case p.s[p.i] == '"':
+			elem, err = p.quoted()
+		default:
+			elem = p.unquoted()
+		}
+		if err != nil {
+			return node, err
+		}
+		node.elems = append(node.elems, elem)
+		if p.i >= len(p.s) {
+			return node, errors.New("invalid PostgreSQL array: " + p.s)
+		}
+		c := p.s[p.i]
+		p.i++
+		if c == '}' {
+			return node, nil
+		}
+		if c != ',' {
+			return node, errors.New("invalid PostgreSQL array: " + p.s)
+		}
+	}
+}
+
+func (p *pgArrayParser) quoted() (pgArrayNode, error) {
+	var b strings.Builder
+	for p.i++; p.i < len(p.s); p.i++ {
+		switch c := p.s[p.i]; c {
+		case '\\':
+			p.i++
+			if p.i < len(p.s) {
+				b.WriteByte(p.s[p.i])
+			}
+		case '"':
+			p.i++
+			return pgArrayNode{text: b.String()}, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return pgArrayNode{}, errors.New("invalid PostgreSQL array: " + p.s)
+}
+
+func (p *pgArrayParser) unquoted() pgArrayNode {
+	start := p.i
+	for p.i < len(p.s) && p.s[p.i] != ',' && p.s[p.i] != '}' {
+		p.i++
+	}
+	text := strings.TrimSpace(p.s[start:p.i])
+	if strings.EqualFold(text, "NULL") {
+		return pgArrayNode{null: true}
+	}
+	return pgArrayNode{text: text}
+}
+
+func decodePgArray(node pgArrayNode, field reflect.Value) error {
+	t := field.Type()
+	if node.null {
+		if t.Kind() != reflect.Pointer && t.Kind() != reflect.Slice {
+			return fmt.Errorf("cannot scan NULL element into %s", t)
+		}
+		field.SetZero()
+		return nil
+	}
+	if t.Kind() == reflect.Pointer {
+		p := reflect.New(t.Elem())
+		if err := decodePgArray(node, p.Elem()); err != nil {
+			return err
+		}
+		field.Set(p)
+		return nil
+	}
+	if t.Kind() == reflect.Slice && t != bytesType {
+		if node.elems == nil {
+			return fmt.Errorf("cannot scan element %q into %s", node.text, t)
+		}
+		s := reflect.MakeSlice(t, len(node.elems), len(node.elems))
+		for i, elem := range node.elems {
+			if err := decodePgArray(elem, s.Index(i)); err != nil {
+				return err
+			}
+		}
+		field.Set(s)
+		return nil
+	}
+	if node.elems != nil {
+		return fmt.Errorf("cannot scan sub array into %s", t)
+	}
+	return decodePgElement(node.text, field)
+}
+
+var pgTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+func decodePgElement(text string, field reflect.Value) (err error) {
+	switch field.Interface().(type) {
+	case time.Time:
+		for _, layout := range pgTimeLayouts {
+			var t time.Time
+			if t, err = time.Parse(layout, text); err == nil {
+				field.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return err
+	case []byte:
+		data, err := hex.DecodeString(strings.TrimPrefix(text, `\x`))
+		if err != nil {
+			return err
+		}
+		field.SetBytes(data)
+		return nil
+	}
+	switch {
+	case field.CanInt():
+		var i int64
+		if i, err = strconv.ParseInt(text, 10, field.Type().Bits()); err == nil {
+			field.SetInt(i)
+		}
+	case field.CanUint():
+		var u uint64
+		if u, err = strconv.ParseUint(text, 10, field.Type().Bits()); err == nil {
+			field.SetUint(u)
+		}
+	case field.CanFloat():
+		var f float64
+		if f, err = strconv.ParseFloat(text, field.Type().Bits()); err == nil {
+			field.SetFloat(f)
+		}
+	case field.Kind() == reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(text); err == nil {
+			field.SetBool(b)
+		}
+	case field.Kind() == reflect.String:
+		field.SetString(text)
+	default:
+		err = fmt.Errorf("unsupported element type %s of PostgreSQL array", field.Type())
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jishaocong0910/gdao"
+	"github.com/stretchr/testify/require"
+)
+
+type Post struct {
+	Id     *int32      `gdao:"column=id;auto"`
+	Tags   []string    `gdao:"column=tags"`
+	Matrix [][]int32   `gdao:"column=matrix"`
+	Refs   []*int64    `gdao:"column=refs"`
+	Files  [][]byte    `gdao:"column=files"`
+	Times  []time.Time `gdao:"column=times"`
+}
+
+func TestPgArray_Value(t *testing.T) {
+	r := require.New(t)
+	value := func(a any) driver.Value {
+		v, err := gdao.PgArray(a).(driver.Valuer).Value()
+		r.NoError(err)
+		return v
+	}
+	r.Equal(`{1,2,3}`, value([]int32{1, 2, 3}))
+	r.Equal(`{}`, value([]int32{}))
+	r.Nil(value([]int32(nil)))
+	r.Nil(value((*[]int32)(nil)))
+	r.Equal(`{"a","b,c","d\"e","f\\g","NULL"}`, value([]string{"a", "b,c", `d"e`, `f\g`, "NULL"}))
+	r.Equal(`{{1,2},{3,4}}`, value([][]int32{{1, 2}, {3, 4}}))
+	r.Equal(`{1,NULL,3}`, value([]*int64{gdao.P[int64](1), nil, gdao.P[int64](3)}))
+	r.Equal(`{t,f}`, value([]bool{true, false}))
+	r.Equal(`{1.5,0.25}`, value([]float32{1.5, 0.25}))
+	r.Equal(`{"\\x0102",NULL}`, value([][]byte{{1, 2}, nil}))
+	r.Equal(`{"2024-05-06T07:08:09.12Z"}`, value([]time.Time{time.Date(2024, 5, 6, 7, 8, 9, 120000000, time.UTC)}))
+	r.Equal(`{1,2}`, gdao.PgArray(gdao.PgArray([]uint{1, 2})).(fmt.Stringer).String())
+	_, err := gdao.PgArray([]any{1}).(driver.Valuer).Value()
+	r.EqualError(err, "unsupported element type interface {} of PostgreSQL array")
+}
+
+func TestPgArray_Scan(t *testing.T) {
+	r := require.New(t)
+	{
+		var tags []string
+		r.NoError(gdao.PgArray(&tags).(sql.Scanner).Scan([]byte(`{a,"b,c","d\"e","f\\g","NULL", h i }`)))
+		r.Equal([]string{"a", "b,c", `d"e`, `f\g`, "NULL", "h i"}, tags)
+		r.NoError(gdao.PgArray(&tags).(sql.Scanner).Scan(nil))
+		r.Nil(tags)
+		r.NoError(gdao.PgArray(&tags).(sql.Scanner).Scan(`{}`))
+		r.Equal([]string{}, tags)
+	}
+	{
+		var matrix [][]int32
+		r.NoError(gdao.PgArray(&matrix).(sql.Scanner).Scan(`[0:1][1:2]={{1,2},{3,4}}`))
+		r.Equal([][]int32{{1, 2}, {3, 4}}, matrix)
+		r.EqualError(gdao.PgArray(&matrix).(sql.Scanner).Scan(`{1,2}`), `cannot scan element "1" into []int32`)
+	}
+	{
+		var refs []*int64
+		r.NoError(gdao.PgArray(&refs).(sql.Scanner).Scan(`{1,NULL,3}`))
+		r.Equal([]*int64{gdao.P[int64](1), nil, gdao.P[int64](3)}, refs)
+		var ids []int64
+		r.EqualError(gdao.PgArray(&ids).(sql.Scanner).Scan(`{1,NULL}`), "cannot scan NULL element into int64")
+	}
+	{
+		var flags []bool
+		r.NoError(gdao.PgArray(&flags).(sql.Scanner).Scan(`{t,f,true}`))
+		r.Equal([]bool{true, false, true}, flags)
+		var files [][]byte
+		r.NoError(gdao.PgArray(&files).(sql.Scanner).Scan(`{"\\x0102",NULL}`))
+		r.Equal([][]byte{{1, 2}, nil}, files)
+		var times []time.Time
+		r.NoError(gdao.PgArray(&times).(sql.Scanner).Scan(`{"2024-05-06 07:08:09.12+08","2024-05-06"}`))
+		r.True(times[0].Equal(time.Date(2024, 5, 6, 7, 8, 9, 120000000, time.FixedZone("", 8*3600))))
+		r.True(times[1].Equal(time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)))
+		var floats []float64
+		r.NoError(gdao.PgArray(&floats).(sql.Scanner).Scan(`{1.5,-2}`))
+		r.Equal([]float64{1.5, -2}, floats)
+	}
+	{
+		var tags []string
+		for _, src := range []any{`{a`, `{"a}`, `a}`, `{a}b`} {
+			r.Error(gdao.PgArray(&tags).(sql.Scanner).Scan(src), src)
+		}
+		r.EqualError(gdao.PgArray(&tags).(sql.Scanner).Scan(1), "cannot scan int into []string")
+	}
+
+	r.NotPanics(func() {
+		gdao.DaoBuilder[Post]().Build()
+	})
+	r.Equal(`SELECT * FROM post WHERE tags @> '{"a","b"}' AND refs = NULL`,
+		gdao.Dialect_.POSTGRES.InlineSql(`SELECT * FROM post WHERE tags @> $1 AND refs = $2`, []any{[]string{"a", "b"}, []*int64(nil)}))
+}
+
+func TestPgArray_ToSql(t *testing.T) {
+	r := require.New(t)
+	db, err := sql.Open("postgres", "")
+	r.NoError(err)
+	dao := gdao.DaoBuilder[Post]().DB(db).Build()
+	value := func(args []any) driver.Value {
+		r.Len(args, 1)
+		r.Implements((*driver.Valuer)(nil), args[0])
+		v, err := args[0].(driver.Valuer).Value()
+		r.NoError(err)
+		return v
+	}
+	_, args, err := dao.Query().BuildSql(func(b *gdao.DaoSqlBuilder[Post]) {
+		b.Write("SELECT * FROM post WHERE tags @> $1", []string{"a"})
+	}).ToSql()
+	r.NoError(err)
+	r.Equal(`{"a"}`, value(args))
+	_, args, err = dao.Exec().BuildSql(func(b *gdao.DaoSqlBuilder[Post]) {
+		b.Write("UPDATE post SET tags = $1", []string{"b"})
+	}).ToSql()
+	r.NoError(err)
+	r.Equal(`{"b"}`, value(args))
+	_, args, err = gdao.CountDaoBuilder().DB(db).Build().Count().BuildSql(func(b *gdao.CountBuilder) {
+		b.Write("SELECT COUNT(*) FROM post WHERE tags @> $1", []string{"c"})
+	}).ToSql()
+	r.NoError(err)
+	r.Equal(`{"c"}`, value(args))
+}
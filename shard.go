@@ -0,0 +1,106 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao
+
+import (
+	"context"
+	"database/sql"
+)
+
+var ctx_key_shard = P("")
+
+// Shard is a physical location of the sharded table, the statements are executed on DB, and the table name is appended
+// with Suffix.
+type Shard struct {
+	DB     *sql.DB
+	Suffix string
+}
+
+// ShardFunc returns the shard by the entity or the shard key carried by ctx, entity is nil for the statements without
+// entities. ok is false if no shard key is found.
+type ShardFunc[T any] func(ctx context.Context, entity *T) (shard Shard, ok bool)
+
+type Sharding[T any] struct {
+	shards    []Shard
+	shardFunc ShardFunc[T]
+	fanOut    bool
+}
+
+// Route returns the shard of the entity or ctx, ok is false if no shard key is found.
+func (s *Sharding[T]) Route(ctx context.Context, entity *T) (shard Shard, ok bool) {
+	return s.shardFunc(ctx, entity)
+}
+
+func (s *Sharding[T]) Shards() []Shard {
+	return s.shards
+}
+
+// FanOut reports whether the reading statements without shard key are executed on all shards, otherwise, they are
+// rejected with ErrNoShardKey.
+func (s *Sharding[T]) FanOut() bool {
+	return s.fanOut
+}
+
+type shardingBuilder[T any] struct {
+	shards    []Shard
+	shardFunc ShardFunc[T]
+	fanOut    bool
+}
+
+// Shards specifies all shards, they are used by fan-out.
+func (b *shardingBuilder[T]) Shards(shards ...Shard) *shardingBuilder[T] {
+	b.shards = shards
+	return b
+}
+
+func (b *shardingBuilder[T]) ShardFunc(shardFunc ShardFunc[T]) *shardingBuilder[T] {
+	b.shardFunc = shardFunc
+	return b
+}
+
+func (b *shardingBuilder[T]) FanOut(fanOut bool) *shardingBuilder[T] {
+	b.fanOut = fanOut
+	return b
+}
+
+func (b *shardingBuilder[T]) Build() *Sharding[T] {
+	if b.shardFunc == nil {
+		panic("shard func must not be nil")
+	}
+	return &Sharding[T]{shards: b.shards, shardFunc: b.shardFunc, fanOut: b.fanOut}
+}
+
+func ShardingBuilder[T any]() *shardingBuilder[T] {
+	return &shardingBuilder[T]{}
+}
+
+// WithShard makes the statements executed on the shard, it is used by the sharded daos.
+func WithShard(ctx context.Context, shard Shard) context.Context {
+	if ctx == nil { // coverage-ignore
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, ctx_key_shard, &shard)
+}
+
+func ShardOf(ctx context.Context) (shard Shard, ok bool) {
+	if ctx != nil {
+		if s, ok := ctx.Value(ctx_key_shard).(*Shard); ok {
+			return *s, true
+		}
+	}
+	return Shard{}, false
+}
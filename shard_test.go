@@ -0,0 +1,79 @@
+/*
+ * Copyright 2024-present jishaocong0910
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gdao_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jishaocong0910/gdao"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharding(t *testing.T) {
+	r := require.New(t)
+	dao, mock := mockUserDao(r)
+	db, shardMock, err := sqlmock.New()
+	r.NoError(err)
+	{
+		_, ok := gdao.ShardOf(context.Background())
+		r.False(ok)
+		ctx := gdao.WithShard(context.Background(), gdao.Shard{DB: db, Suffix: "_1"})
+		shard, ok := gdao.ShardOf(ctx)
+		r.True(ok)
+		r.Equal("_1", shard.Suffix)
+
+		shardMock.ExpectPrepare(`SELECT id FROM user_1`).ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		_, _, err = dao.Query().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("SELECT id FROM user" + shard.Suffix)
+		}).Do()
+		r.NoError(err)
+
+		shardMock.ExpectBegin()
+		shardMock.ExpectPrepare(`DELETE FROM user_1`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		shardMock.ExpectCommit()
+		err = gdao.Tx(ctx, func(ctx context.Context) error {
+			_, err := dao.Exec().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+				b.Write("DELETE FROM user" + shard.Suffix)
+			}).Do()
+			return err
+		}, gdao.WithDefaultTx(db, nil))
+		r.NoError(err)
+		r.NoError(shardMock.ExpectationsWereMet())
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		shards := []gdao.Shard{{DB: db, Suffix: "_0"}}
+		sharding := gdao.ShardingBuilder[User]().Shards(shards...).FanOut(true).
+			ShardFunc(func(ctx context.Context, entity *User) (gdao.Shard, bool) {
+				return shards[0], entity != nil
+			}).Build()
+		r.Equal(shards, sharding.Shards())
+		r.True(sharding.FanOut())
+		_, ok := sharding.Route(context.Background(), nil)
+		r.False(ok)
+		shard, ok := sharding.Route(context.Background(), &User{})
+		r.True(ok)
+		r.Equal("_0", shard.Suffix)
+	}
+	{
+		r.PanicsWithValue("shard func must not be nil", func() {
+			gdao.ShardingBuilder[User]().Build()
+		})
+	}
+}
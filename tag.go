@@ -24,8 +24,11 @@ import (
 
 type tag struct {
 	column            string
+	isPrimaryKey      bool
 	isAutoIncrement   bool
 	autoIncrementStep int64
+	isSensitive       bool
+	isJSON            bool
 }
 
 func parseTag(tf reflect.StructField) tag {
@@ -36,8 +39,15 @@ func parseTag(tf reflect.StructField) tag {
 			kv := strings.Split(p, "=")
 			if len(kv) == 1 {
 				p = strings.TrimSpace(p)
-				if p == "auto" {
+				switch p {
+				case "pk":
+					t.isPrimaryKey = true
+				case "auto":
 					t.isAutoIncrement = true
+				case "sensitive":
+					t.isSensitive = true
+				case "json":
+					t.isJSON = true
 				}
 			}
 			if len(kv) == 2 {
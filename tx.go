@@ -21,6 +21,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	pkgErrors "github.com/pkg/errors"
 )
@@ -30,9 +34,40 @@ var ctx_key_tx = P("")
 type TxOption func(*txOption)
 
 type txOption struct {
-	db   *sql.DB
-	opts *sql.TxOptions
-	must bool
+	db          *sql.DB
+	opts        *sql.TxOptions
+	must        bool
+	propagation Propagation
+	maxAttempts int
+	backoff     func(attempt int) time.Duration
+	classifier  func(err error) bool
+	timeout     time.Duration
+	readOnly    bool
+	isolation   sql.IsolationLevel
+	label       string
+}
+
+// txCtx is the transaction bound to a context, depth is the number of savepoints created by the nested Tx calls.
+type txCtx struct {
+	tx     *sql.Tx
+	db     *sql.DB
+	depth  int
+	syntax savepointSyntax
+	parent *txCtx
+	// the transaction bound to the context before, it may be on another *sql.DB.
+	next  *txCtx
+	label string
+	// if true, the transaction is bound by SetRollbackTx and the Tx calls directly in it create savepoints, see SetRollbackTx.
+	rollbackOnly bool
+	// if true, the transaction is bound by SetTx, SetDBTx or SetRollbackTx and never ended by Tx.
+	bound bool
+	// the number of statements executed in the transaction, shared by the savepoints.
+	stmts *atomic.Int64
+	begin time.Time
+
+	mu             sync.Mutex
+	afterCommits   []func()
+	afterRollbacks []func(err error)
 }
 
 func Tx(ctx context.Context, do func(ctx context.Context) error, opts ...TxOption) (err error) {
@@ -44,71 +79,401 @@ func Tx(ctx context.Context, do func(ctx context.Context) error, opts ...TxOptio
 	for _, opt := range opts { // coverage-ignore
 		opt(o)
 	}
+	if o.propagation.IsUndefined() {
+		o.propagation = Propagation_.NESTED
+	}
 
-	tx := getTx(ctx)
-	if tx == nil {
-		var db *sql.DB
-		if o.db == nil { // coverage-ignore
-			db = global.DefaultDB
-		}
-		if db == nil { // coverage-ignore
-			err := errors.New(`cannot begin a transaction, no available *sql.DB`)
-			checkMust(o.must, err)
-			return err
+	for attempt := 1; ; attempt++ {
+		var begun bool
+		begun, err = runTx(ctx, do, o)
+		if err == nil || !begun || attempt >= o.maxAttempts || !o.retryable(err) {
+			break
 		}
-		tx, err = db.BeginTx(ctx, o.opts)
-		if err != nil { // coverage-ignore
-			checkMust(o.must, err)
-			return err
+		printWarn(ctx, fmt.Errorf("transaction failed, retry %d/%d: %w", attempt, o.maxAttempts-1, err))
+		if e := sleep(ctx, o.backoff, attempt); e != nil {
+			err = e
+			break
 		}
-		ctx = SetTx(ctx, tx)
+	}
+	checkMust(o.must, err)
+	return err
+}
+
+// runTx executes do once according to the propagation, begun reports whether a new transaction is begun.
+func runTx(ctx context.Context, do func(ctx context.Context) error, o *txOption) (begun bool, err error) {
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+	db := o.db
+	if db == nil {
+		db = globalCfg().DefaultDB
+	}
+	tc := lookupTxCtx(ctx, db)
+	var owned bool
+	switch {
+	case tc == nil && o.propagation.Is(Propagation_.MANDATORY):
+		err = ErrNoTx
+	case tc == nil && o.propagation.Is(Propagation_.NEVER, Propagation_.SUPPORTS):
+		// execute without a transaction
+	case tc != nil && o.propagation.Is(Propagation_.NEVER):
+		err = ErrExistingTx
+	case tc != nil && tc.rollbackOnly && tc.depth == 0 && o.propagation.Is(Propagation_.REQUIRED, Propagation_.REQUIRES_NEW):
+		tc, err = tc.nest(ctx, o, db)
+		owned = true
+	case tc != nil && o.propagation.Is(Propagation_.REQUIRED, Propagation_.MANDATORY, Propagation_.SUPPORTS):
+		// join the existing transaction
+	case tc != nil && o.propagation.Is(Propagation_.NESTED):
+		tc, err = tc.nest(ctx, o, db)
+		owned = true
+	default:
+		tc, err = beginTx(ctx, o, db)
+		owned, begun = true, true
+	}
+	if err != nil {
+		return false, err
+	}
+	if owned {
+		ctx = bindTxCtx(ctx, tc)
 	}
 
 	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else if r := recover(); r != nil {
-			tx.Rollback()
+		if r := recover(); r != nil {
 			if e, ok := r.(error); ok {
 				err = pkgErrors.WithStack(e)
 			} else {
 				err = pkgErrors.WithStack(fmt.Errorf("%v", r))
 			}
-		} else {
-			tx.Commit()
 		}
-		checkMust(o.must, err)
+		if owned {
+			if e := tc.end(ctx, err); err == nil {
+				err = e
+			}
+		}
 	}()
 	err = do(ctx)
-	return err
+	return
 }
 
 func SetTx(ctx context.Context, tx *sql.Tx) context.Context {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	ctx = context.WithValue(ctx, ctx_key_tx, tx)
-	return ctx
+	return bindTxCtx(ctx, &txCtx{tx: tx, bound: true})
 }
 
-func WithDefaultTx(db *sql.DB, opts *sql.TxOptions) TxOption { // coverage-ignore
+func SetDBTx(ctx context.Context, db *sql.DB, tx *sql.Tx) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return bindTxCtx(ctx, &txCtx{tx: tx, db: db, bound: true})
+}
+
+// SetRollbackTx binds tx to db like SetDBTx for the tests which roll back tx at last. The Tx calls directly in ctx
+// create savepoints in tx even if they require a new transaction, so that nothing is committed. The Tx calls inside
+// them follow the propagation as usual.
+func SetRollbackTx(ctx context.Context, db *sql.DB, tx *sql.Tx) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return bindTxCtx(ctx, &txCtx{tx: tx, db: db, rollbackOnly: true, bound: true})
+}
+
+// BoundTxDone reports that tx bound by SetTx, SetDBTx or SetRollbackTx is committed or rolled back. The cache of the
+// tables written in tx is invalidated, and they are cached again, the tables are never cached without the call.
+func BoundTxDone(tx *sql.Tx) {
+	endBoundWrites(tx)
+}
+
+func WithDefaultTx(db *sql.DB, opts *sql.TxOptions) TxOption {
 	return func(o *txOption) {
 		o.db = db
 		o.opts = opts
 	}
 }
 
+func WithTimeout(timeout time.Duration) TxOption {
+	return func(o *txOption) {
+		o.timeout = timeout
+	}
+}
+
+func WithReadOnly() TxOption {
+	return func(o *txOption) {
+		o.readOnly = true
+	}
+}
+
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(o *txOption) {
+		o.isolation = level
+	}
+}
+
+// WithLabel names the transaction in the logs, the nested transactions inherit it if they are not labeled.
+func WithLabel(label string) TxOption {
+	return func(o *txOption) {
+		o.label = label
+	}
+}
+
 func WithMust() TxOption {
 	return func(o *txOption) {
 		o.must = true
 	}
 }
 
-func getTx(ctx context.Context) *sql.Tx {
+// WithPropagation specifies how Tx works with the transaction in ctx, the default is NESTED rather than REQUIRED, so
+// that a Tx call in a transaction creates a savepoint without the option.
+func WithPropagation(propagation Propagation) TxOption {
+	return func(o *txOption) {
+		o.propagation = propagation
+	}
+}
+
+// WithRetry re-executes the transaction when it fails with a retryable error, the total attempts are no more than
+// maxAttempts, and backoff returns the waiting duration before the next attempt. It takes effect only if Tx begins a
+// new transaction.
+func WithRetry(maxAttempts int, backoff func(attempt int) time.Duration) TxOption {
+	return func(o *txOption) {
+		o.maxAttempts = maxAttempts
+		o.backoff = backoff
+	}
+}
+
+// WithRetryClassifier specifies which errors are retryable, default is IsRetryable.
+func WithRetryClassifier(classifier func(err error) bool) TxOption {
+	return func(o *txOption) {
+		o.classifier = classifier
+	}
+}
+
+func (o *txOption) retryable(err error) bool {
+	if o.classifier != nil {
+		return o.classifier(err)
+	}
+	return IsRetryable(err)
+}
+
+func sleep(ctx context.Context, backoff func(attempt int) time.Duration, attempt int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if backoff == nil {
+		return nil
+	}
+	timer := time.NewTimer(backoff(attempt))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// getTxCtx returns the transaction bound to ctx lastly.
+func getTxCtx(ctx context.Context) *txCtx {
 	if ctx != nil {
-		if tx, ok := ctx.Value(ctx_key_tx).(*sql.Tx); ok {
-			return tx
+		if tc, ok := ctx.Value(ctx_key_tx).(*txCtx); ok {
+			return tc
 		}
 	}
 	return nil
 }
+
+// lookupTxCtx returns the transaction bound to ctx lastly for db, the transactions bound by SetTx are for any db.
+func lookupTxCtx(ctx context.Context, db *sql.DB) *txCtx {
+	for tc := getTxCtx(ctx); tc != nil; tc = tc.next {
+		if tc.db == nil || db == nil || tc.db == db {
+			return tc
+		}
+	}
+	return nil
+}
+
+func bindTxCtx(ctx context.Context, tc *txCtx) context.Context {
+	tc.next = getTxCtx(ctx)
+	return context.WithValue(ctx, ctx_key_tx, tc)
+}
+
+func (o *txOption) txOptions() *sql.TxOptions {
+	var opts sql.TxOptions
+	if o.opts != nil {
+		opts = *o.opts
+	}
+	if o.readOnly {
+		opts.ReadOnly = true
+	}
+	if o.isolation != sql.LevelDefault {
+		opts.Isolation = o.isolation
+	}
+	return &opts
+}
+
+// beginTx begins a new transaction on db.
+func beginTx(ctx context.Context, o *txOption, db *sql.DB) (*txCtx, error) {
+	if db == nil { // coverage-ignore
+		return nil, errors.New(`cannot begin a transaction, no available *sql.DB`)
+	}
+	tx, err := db.BeginTx(ctx, o.txOptions())
+	if err != nil { // coverage-ignore
+		return nil, err
+	}
+	return &txCtx{tx: tx, db: db, label: o.label, stmts: new(atomic.Int64), begin: time.Now()}, nil
+}
+
+// nest creates a savepoint in the transaction.
+func (tc *txCtx) nest(ctx context.Context, o *txOption, db *sql.DB) (*txCtx, error) {
+	if tc.db != nil {
+		db = tc.db
+	}
+	label := o.label
+	if label == "" {
+		label = tc.label
+	}
+	nested := &txCtx{tx: tc.tx, db: tc.db, depth: tc.depth + 1, parent: tc, label: label, stmts: tc.stmts}
+	err := nested.savepoint(ctx, DialectOf(db).savepointSyntax())
+	if err != nil { // coverage-ignore
+		return nil, err
+	}
+	return nested, nil
+}
+
+func (tc *txCtx) savepointName() string {
+	return "gdao_sp_" + strconv.Itoa(tc.depth)
+}
+
+func (tc *txCtx) savepoint(ctx context.Context, syntax savepointSyntax) error {
+	tc.syntax = syntax
+	_, err := tc.tx.ExecContext(ctx, syntax.save+tc.savepointName())
+	return err
+}
+
+// end commits or rolls back the transaction if it is the outermost, otherwise, releases or rolls back to the savepoint.
+// The callbacks of a released savepoint are handed over to the outer, and they are fired after the outermost ends.
+func (tc *txCtx) end(ctx context.Context, err error) error {
+	if tc.depth == 0 {
+		if err != nil {
+			defer tc.checkSlow(ctx)
+			if tc.tx.Rollback() == nil {
+				tc.fireAfterRollbacks(ctx, err)
+			}
+			return nil
+		}
+		defer tc.checkSlow(ctx)
+		if e := tc.tx.Commit(); e != nil { // coverage-ignore
+			return e
+		}
+		tc.fireAfterCommits(ctx)
+		return nil
+	}
+	if err != nil {
+		_, e := tc.tx.ExecContext(context.WithoutCancel(ctx), tc.syntax.rollbackTo+tc.savepointName())
+		printWarn(ctx, e)
+		if e == nil {
+			tc.fireAfterRollbacks(ctx, err)
+		}
+		return nil
+	}
+	if tc.syntax.release != "" {
+		_, e := tc.tx.ExecContext(ctx, tc.syntax.release+tc.savepointName())
+		printWarn(ctx, e)
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.parent.bound {
+		if len(tc.afterCommits)+len(tc.afterRollbacks) > 0 {
+			printWarn(ctx, errDroppedCallback)
+		}
+		return nil
+	}
+	tc.parent.mu.Lock()
+	defer tc.parent.mu.Unlock()
+	tc.parent.afterCommits = append(tc.parent.afterCommits, tc.afterCommits...)
+	tc.parent.afterRollbacks = append(tc.parent.afterRollbacks, tc.afterRollbacks...)
+	return nil
+}
+
+func (tc *txCtx) countStmt() {
+	if tc.stmts != nil {
+		tc.stmts.Add(1)
+	}
+}
+
+func (tc *txCtx) checkSlow(ctx context.Context) {
+	threshold := globalCfg().SlowTxThreshold
+	if threshold <= 0 {
+		return
+	}
+	if elapsed := time.Since(tc.begin); elapsed > threshold {
+		printWarn(ctx, fmt.Errorf("slow transaction, label: %s, elapsed: %s, statements: %d", tc.label, elapsed, tc.stmts.Load()))
+	}
+}
+
+func (tc *txCtx) fireAfterCommits(ctx context.Context) {
+	tc.mu.Lock()
+	fns := tc.afterCommits
+	tc.mu.Unlock()
+	for _, fn := range fns {
+		callback(ctx, fn)
+	}
+}
+
+func (tc *txCtx) fireAfterRollbacks(ctx context.Context, err error) {
+	tc.mu.Lock()
+	fns := tc.afterRollbacks
+	tc.mu.Unlock()
+	for _, fn := range fns {
+		callback(ctx, func() { fn(err) })
+	}
+}
+
+// callback calls fn, and the panic of it is logged, so that the other callbacks are not affected.
+func callback(ctx context.Context, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			printWarn(ctx, fmt.Errorf("transaction callback panic: %v", r))
+		}
+	}()
+	fn()
+}
+
+var errDroppedCallback = errors.New("transaction callback is dropped, the transaction bound by SetTx, SetDBTx or SetRollbackTx never fires callbacks")
+
+// AfterCommit registers fn to be called after the transaction in ctx is committed, fn is called immediately if ctx has
+// no transaction. Only the transactions begun by Tx fire the callbacks, fn is dropped with a warning if the transaction
+// is bound by SetTx, SetDBTx or SetRollbackTx.
+func AfterCommit(ctx context.Context, fn func()) {
+	tc := getTxCtx(ctx)
+	if tc == nil {
+		fn()
+		return
+	}
+	if tc.bound {
+		printWarn(ctx, errDroppedCallback)
+		return
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.afterCommits = append(tc.afterCommits, fn)
+}
+
+// AfterRollback registers fn to be called with the cause after the transaction or the savepoint in ctx is rolled back,
+// fn is called immediately with a nil error if ctx has no transaction. Only the transactions begun by Tx fire the
+// callbacks, fn is dropped with a warning if the transaction is bound by SetTx, SetDBTx or SetRollbackTx.
+func AfterRollback(ctx context.Context, fn func(err error)) {
+	tc := getTxCtx(ctx)
+	if tc == nil {
+		fn(nil)
+		return
+	}
+	if tc.bound {
+		printWarn(ctx, errDroppedCallback)
+		return
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.afterRollbacks = append(tc.afterRollbacks, fn)
+}
@@ -18,11 +18,20 @@ package gdao_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jishaocong0910/gdao"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	mssql "github.com/microsoft/go-mssqldb"
+	_ "github.com/sijms/go-ora/v2"
+	"github.com/sijms/go-ora/v2/network"
 	"github.com/stretchr/testify/require"
 )
 
@@ -97,5 +106,532 @@ func TestTx(t *testing.T) {
 			}, gdao.WithMust())
 		})
 	}
+	{
+		userDao, mock := mockUserDao(r)
+		mock.ExpectBegin()
+		mock.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`ROLLBACK TO SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			_, err := userDao.Exec().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+				b.Write("UPDATE user set status=1 WHERE id=?", 1)
+			}).Do()
+			r.NoError(err)
+			err = gdao.Tx(ctx, func(ctx context.Context) error {
+				return errors.New("inner error")
+			})
+			r.EqualError(err, "inner error")
+			return nil
+		})
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		_, mock := mockUserDao(r)
+		db, dbMock, err := sqlmock.New()
+		r.NoError(err)
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+		err = gdao.Tx(nil, func(ctx context.Context) error {
+			return nil
+		}, gdao.WithDefaultTx(db, nil))
+		r.NoError(err)
+		r.NoError(dbMock.ExpectationsWereMet())
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		userDao, mock := mockUserDao(r)
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`SAVEPOINT gdao_sp_2`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`RELEASE SAVEPOINT gdao_sp_2`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`ROLLBACK TO SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			return gdao.Tx(ctx, func(ctx context.Context) error {
+				err := gdao.Tx(ctx, func(ctx context.Context) error {
+					_, err := userDao.Exec().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+						b.Write("UPDATE user set status=1 WHERE id=?", 1)
+					}).Do()
+					return err
+				})
+				r.NoError(err)
+				panic("inner panic")
+			})
+		})
+		r.EqualError(err, "inner panic")
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		_, mock := mockUserDao(r)
+		db, dbMock, err := sqlmock.New()
+		r.NoError(err)
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(`SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectExec(`RELEASE SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectCommit()
+		tx, err := db.Begin()
+		r.NoError(err)
+		err = gdao.Tx(gdao.SetTx(nil, tx), func(ctx context.Context) error {
+			return nil
+		})
+		r.NoError(err)
+		r.NoError(tx.Commit())
+		r.NoError(dbMock.ExpectationsWereMet())
+		r.NoError(mock.ExpectationsWereMet())
+	}
+}
+
+func TestTx_Propagation(t *testing.T) {
+	r := require.New(t)
+	update := func(ctx context.Context, userDao *gdao.Dao[User]) error {
+		_, err := userDao.Exec().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("UPDATE user set status=1 WHERE id=?", 1)
+		}).Do()
+		return err
+	}
+	{
+		userDao, mock := mockUserDao(r)
+		mock.ExpectBegin()
+		mock.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			return gdao.Tx(ctx, func(ctx context.Context) error {
+				r.NoError(update(ctx, userDao))
+				return errors.New("inner error")
+			}, gdao.WithPropagation(gdao.Propagation_.REQUIRED))
+		})
+		r.EqualError(err, "inner error")
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	for _, opts := range [][]gdao.TxOption{nil, {gdao.WithPropagation(gdao.Propagation_.NESTED)}} {
+		// without the option, the propagation is NESTED rather than REQUIRED
+		userDao, mock := mockUserDao(r)
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`ROLLBACK TO SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			err := gdao.Tx(ctx, func(ctx context.Context) error {
+				r.NoError(update(ctx, userDao))
+				return errors.New("inner error")
+			}, opts...)
+			r.EqualError(err, "inner error")
+			return nil
+		})
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		userDao, mock := mockUserDao(r)
+		mock.ExpectBegin()
+		mock.ExpectBegin()
+		mock.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectRollback()
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			err := gdao.Tx(ctx, func(ctx context.Context) error {
+				return update(ctx, userDao)
+			}, gdao.WithPropagation(gdao.Propagation_.REQUIRES_NEW))
+			r.NoError(err)
+			return errors.New("outer error")
+		})
+		r.EqualError(err, "outer error")
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		userDao, mock := mockUserDao(r)
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`RELEASE SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			return gdao.Tx(ctx, func(ctx context.Context) error {
+				return update(ctx, userDao)
+			}, gdao.WithPropagation(gdao.Propagation_.NESTED))
+		})
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		_, mock := mockUserDao(r)
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			return nil
+		}, gdao.WithPropagation(gdao.Propagation_.MANDATORY))
+		r.ErrorIs(err, gdao.ErrNoTx)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		_, mock := mockUserDao(r)
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			return gdao.Tx(ctx, func(ctx context.Context) error {
+				return nil
+			}, gdao.WithPropagation(gdao.Propagation_.NEVER))
+		})
+		r.ErrorIs(err, gdao.ErrExistingTx)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		userDao, mock := mockUserDao(r)
+		mock.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			return update(ctx, userDao)
+		}, gdao.WithPropagation(gdao.Propagation_.SUPPORTS))
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		userDao, mock := mockUserDao(r)
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`SAVEPOINT gdao_sp_2`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`ROLLBACK TO SAVEPOINT gdao_sp_2`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`RELEASE SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+		tx, err := userDao.DB().Begin()
+		r.NoError(err)
+		ctx := gdao.SetRollbackTx(nil, userDao.DB(), tx)
+		err = gdao.Tx(ctx, func(ctx context.Context) error {
+			err := gdao.Tx(ctx, func(ctx context.Context) error {
+				r.NoError(update(ctx, userDao))
+				return errors.New("inner error")
+			}, gdao.WithPropagation(gdao.Propagation_.NESTED))
+			r.EqualError(err, "inner error")
+			return nil
+		}, gdao.WithPropagation(gdao.Propagation_.REQUIRES_NEW))
+		r.NoError(err)
+		r.NoError(tx.Rollback())
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		userDao, mock := mockUserDao(r)
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`ROLLBACK TO SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+		tx, err := userDao.DB().Begin()
+		r.NoError(err)
+		ctx := gdao.SetRollbackTx(nil, userDao.DB(), tx)
+		err = gdao.Tx(ctx, func(ctx context.Context) error {
+			return gdao.Tx(ctx, func(ctx context.Context) error {
+				r.NoError(update(ctx, userDao))
+				return errors.New("inner error")
+			}, gdao.WithPropagation(gdao.Propagation_.REQUIRED))
+		}, gdao.WithPropagation(gdao.Propagation_.REQUIRED))
+		r.EqualError(err, "inner error")
+		r.NoError(tx.Rollback())
+		r.NoError(mock.ExpectationsWereMet())
+	}
+}
+
+func TestTx_Retry(t *testing.T) {
+	r := require.New(t)
+	update := func(ctx context.Context, userDao *gdao.Dao[User]) error {
+		_, err := userDao.Exec().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("UPDATE user set status=1 WHERE id=?", 1)
+		}).Do()
+		return err
+	}
+	{
+		userDao, mock := mockUserDao(r)
+		mock.ExpectBegin()
+		mock.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnError(&mysql.MySQLError{Number: 1213})
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		var attempts []int
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			return update(ctx, userDao)
+		}, gdao.WithRetry(3, func(attempt int) time.Duration {
+			attempts = append(attempts, attempt)
+			return time.Millisecond
+		}))
+		r.NoError(err)
+		r.Equal([]int{1}, attempts)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		_, mock := mockUserDao(r)
+		for i := 0; i < 2; i++ {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+		}
+		var n int
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			n++
+			return errors.New("retryable")
+		}, gdao.WithRetry(2, nil), gdao.WithRetryClassifier(func(err error) bool {
+			return err.Error() == "retryable"
+		}))
+		r.EqualError(err, "retryable")
+		r.Equal(2, n)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		_, mock := mockUserDao(r)
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+		var n int
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			n++
+			return errors.New("not retryable")
+		}, gdao.WithRetry(3, nil))
+		r.EqualError(err, "not retryable")
+		r.Equal(1, n)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		_, mock := mockUserDao(r)
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+		ctx, cancel := context.WithCancel(context.Background())
+		err := gdao.Tx(ctx, func(ctx context.Context) error {
+			return &mysql.MySQLError{Number: 1205}
+		}, gdao.WithRetry(3, func(int) time.Duration {
+			cancel()
+			return time.Hour
+		}))
+		r.ErrorIs(err, context.Canceled)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+}
+
+func TestTx_Callback(t *testing.T) {
+	r := require.New(t)
+	{
+		_, mock := mockUserDao(r)
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`RELEASE SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`ROLLBACK TO SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		var events []string
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			gdao.AfterCommit(ctx, func() { events = append(events, "commit1") })
+			gdao.AfterRollback(ctx, func(err error) { events = append(events, "rollback1") })
+			r.NoError(gdao.Tx(ctx, func(ctx context.Context) error {
+				gdao.AfterCommit(ctx, func() { events = append(events, "commit2") })
+				return nil
+			}))
+			r.Error(gdao.Tx(ctx, func(ctx context.Context) error {
+				gdao.AfterCommit(ctx, func() { events = append(events, "commit3") })
+				gdao.AfterRollback(ctx, func(err error) { events = append(events, "rollback3: "+err.Error()) })
+				return errors.New("error3")
+			}))
+			gdao.AfterCommit(ctx, func() { panic("panic in callback") })
+			gdao.AfterCommit(ctx, func() { events = append(events, "commit4") })
+			r.Equal([]string{"rollback3: error3"}, events)
+			return nil
+		})
+		r.NoError(err)
+		r.Equal([]string{"rollback3: error3", "commit1", "commit2", "commit4"}, events)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		_, mock := mockUserDao(r)
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+		var events []string
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			gdao.AfterCommit(ctx, func() { events = append(events, "commit") })
+			gdao.AfterRollback(ctx, func(err error) { events = append(events, "rollback: "+err.Error()) })
+			return errors.New("error")
+		})
+		r.EqualError(err, "error")
+		r.Equal([]string{"rollback: error"}, events)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+	{
+		var events []string
+		gdao.AfterCommit(context.Background(), func() { events = append(events, "commit") })
+		gdao.AfterRollback(nil, func(err error) { events = append(events, fmt.Sprint("rollback: ", err)) })
+		r.Equal([]string{"commit", "rollback: <nil>"}, events)
+	}
+	{
+		db, mock, err := sqlmock.New()
+		r.NoError(err)
+		log := &MockLogger{}
+		gdao.Config(gdao.Cfg{DefaultDB: db, Logger: log})
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`ROLLBACK TO SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`RELEASE SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		tx, err := db.Begin()
+		r.NoError(err)
+		var events []string
+		ctx := gdao.SetDBTx(nil, db, tx)
+		gdao.AfterCommit(ctx, func() { events = append(events, "commit") })
+		r.Equal("transaction callback is dropped, the transaction bound by SetTx, SetDBTx or SetRollbackTx never fires callbacks", log.msg)
+		log.msg = ""
+		gdao.AfterRollback(ctx, func(err error) { events = append(events, "rollback") })
+		r.NotEmpty(log.msg)
+		r.Error(gdao.Tx(ctx, func(ctx context.Context) error {
+			gdao.AfterRollback(ctx, func(err error) { events = append(events, "savepoint rollback") })
+			return errors.New("error")
+		}))
+		log.msg = ""
+		r.NoError(gdao.Tx(ctx, func(ctx context.Context) error {
+			gdao.AfterCommit(ctx, func() { events = append(events, "savepoint commit") })
+			return nil
+		}))
+		r.NotEmpty(log.msg)
+		r.NoError(tx.Commit())
+		r.Equal([]string{"savepoint rollback"}, events)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+}
+
+func TestTx_MultiDB(t *testing.T) {
+	r := require.New(t)
+	dbA, mockA, err := sqlmock.New()
+	r.NoError(err)
+	dbB, mockB, err := sqlmock.New()
+	r.NoError(err)
+	daoA := gdao.DaoBuilder[User]().DB(dbA).Build()
+	daoB := gdao.DaoBuilder[User]().DB(dbB).Build()
+	update := func(ctx context.Context, userDao *gdao.Dao[User]) error {
+		_, err := userDao.Exec().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+			b.Write("UPDATE user set status=1 WHERE id=?", 1)
+		}).Do()
+		return err
+	}
+	{
+		mockA.ExpectBegin()
+		mockA.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mockB.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mockB.ExpectBegin()
+		mockB.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mockB.ExpectRollback()
+		mockA.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mockA.ExpectCommit()
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			r.NoError(update(ctx, daoA))
+			r.NoError(update(ctx, daoB))
+			err := gdao.Tx(ctx, func(ctx context.Context) error {
+				r.NoError(update(ctx, daoB))
+				return errors.New("error")
+			}, gdao.WithDefaultTx(dbB, nil))
+			r.EqualError(err, "error")
+			return update(ctx, daoA)
+		}, gdao.WithDefaultTx(dbA, nil))
+		r.NoError(err)
+		r.NoError(mockA.ExpectationsWereMet())
+		r.NoError(mockB.ExpectationsWereMet())
+	}
+	{
+		mockA.ExpectBegin()
+		mockB.ExpectBegin()
+		mockA.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mockB.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mockA.ExpectCommit()
+		mockB.ExpectCommit()
+		txA, err := dbA.Begin()
+		r.NoError(err)
+		txB, err := dbB.Begin()
+		r.NoError(err)
+		ctx := gdao.SetDBTx(gdao.SetDBTx(nil, dbA, txA), dbB, txB)
+		r.NoError(update(ctx, daoA))
+		r.NoError(update(ctx, daoB))
+		r.NoError(txA.Commit())
+		r.NoError(txB.Commit())
+		r.NoError(mockA.ExpectationsWereMet())
+		r.NoError(mockB.ExpectationsWereMet())
+	}
+}
+
+func TestTx_Options(t *testing.T) {
+	r := require.New(t)
+	{
+		r.Equal(&sql.TxOptions{}, gdao.ExportTxOptions())
+		r.Equal(&sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true},
+			gdao.ExportTxOptions(gdao.WithDefaultTx(nil, &sql.TxOptions{Isolation: sql.LevelReadCommitted}), gdao.WithReadOnly(), gdao.WithIsolation(sql.LevelSerializable)))
+	}
+	{
+		userDao, mock := mockUserDao(r)
+		log := &MockLogger{}
+		gdao.Config(gdao.Cfg{DefaultDB: userDao.DB(), Logger: log, LogLevel: gdao.LogLevel_.INFO, SlowTxThreshold: time.Nanosecond})
+		mock.ExpectBegin()
+		mock.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectPrepare(`UPDATE user set status=1 WHERE id=\?`).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`RELEASE SAVEPOINT gdao_sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			_, err := userDao.Exec().Ctx(ctx).Desc("update").BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+				b.Write("UPDATE user set status=1 WHERE id=?", 1)
+			}).Do()
+			r.NoError(err)
+			r.Equal("Tx: %s, Desc: %s, SQL: %s; args: %v, affected: %d", log.msg)
+			r.Equal("order", log.args[0])
+			return gdao.Tx(ctx, func(ctx context.Context) error {
+				_, err := userDao.Exec().Ctx(ctx).BuildSql(func(b *gdao.DaoSqlBuilder[User]) {
+					b.Write("UPDATE user set status=1 WHERE id=?", 1)
+				}).Do()
+				r.Equal("order", log.args[0])
+				return err
+			})
+		}, gdao.WithLabel("order"))
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+		r.Regexp(`^slow transaction, label: order, elapsed: .+, statements: 2$`, log.msg)
+	}
+	{
+		_, mock := mockUserDao(r)
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+		err := gdao.Tx(nil, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, gdao.WithTimeout(time.Millisecond))
+		r.ErrorIs(err, context.DeadlineExceeded)
+		r.NoError(mock.ExpectationsWereMet())
+	}
+}
+
+func TestDialect_Retryable(t *testing.T) {
+	r := require.New(t)
+	r.True(gdao.Dialect_.MYSQL.Retryable(fmt.Errorf("wrap: %w", &mysql.MySQLError{Number: 1213})))
+	r.False(gdao.Dialect_.MYSQL.Retryable(&mysql.MySQLError{Number: 1062}))
+	r.True(gdao.Dialect_.POSTGRES.Retryable(&pq.Error{Code: "40P01"}))
+	r.True(gdao.Dialect_.POSTGRES.Retryable(&pq.Error{Code: "40001"}))
+	r.True(gdao.Dialect_.ORACLE.Retryable(&network.OracleError{ErrCode: 60}))
+	r.True(gdao.Dialect_.SQLSERVER.Retryable(mssql.Error{Number: 1205}))
+	r.True(gdao.Dialect_.SQLITE.Retryable(sqlite3.Error{Code: sqlite3.ErrBusy}))
+	r.False(gdao.Dialect_.SQLITE.Retryable(&mysql.MySQLError{Number: 1213}))
+	r.True(gdao.IsRetryable(&pq.Error{Code: "40001"}))
+	r.False(gdao.IsRetryable(errors.New("error")))
+	code, ok := gdao.Dialect_.SQLSERVER.ErrorCode(mssql.Error{Number: 2627})
+	r.True(ok)
+	r.Equal("2627", code)
+}
 
+func TestDialectOf(t *testing.T) {
+	r := require.New(t)
+	for driverName, dialect := range map[string]gdao.Dialect{
+		"mysql":    gdao.Dialect_.MYSQL,
+		"postgres": gdao.Dialect_.POSTGRES,
+		"oracle":   gdao.Dialect_.ORACLE,
+		"mssql":    gdao.Dialect_.SQLSERVER,
+		"sqlite3":  gdao.Dialect_.SQLITE,
+	} {
+		db, err := sql.Open(driverName, "")
+		r.NoError(err)
+		r.Equal(dialect, gdao.DialectOf(db), driverName)
+	}
+	db, _, err := sqlmock.New()
+	r.NoError(err)
+	r.True(gdao.DialectOf(db).IsUndefined())
+	r.True(gdao.DialectOf(nil).IsUndefined())
 }